@@ -0,0 +1,130 @@
+package linebotapi
+
+import (
+    "fmt"
+    "sync"
+    "context"
+)
+
+// MaxRecipients is the maximum number of mids accepted in the To list of a
+// single event.
+const MaxRecipients = 150
+
+type BroadcastOptions struct {
+    // ChunkSize defaults to MaxRecipients.
+    ChunkSize int
+    // Concurrency is the number of chunks sent in parallel and defaults to 4.
+    Concurrency int
+}
+
+// ChunkResult is the outcome of sending one chunk of recipients. Index is
+// the position of the chunk in the recipient list.
+type ChunkResult struct {
+    Index int
+    To []string
+    Err error
+}
+
+type BroadcastReport struct {
+    Chunks []ChunkResult
+}
+
+// Failed returns the chunks that could not be sent.
+func (r *BroadcastReport) Failed() []ChunkResult {
+    var failed []ChunkResult
+    for _, chunk := range r.Chunks {
+        if chunk.Err != nil {
+            failed = append(failed, chunk)
+        }
+    }
+    return failed
+}
+
+// Err returns nil when every chunk was sent, or an error wrapping the error
+// of the first failed chunk.
+func (r *BroadcastReport) Err() error {
+    failed := r.Failed()
+    if len(failed) == 0 {
+        return nil
+    }
+    return fmt.Errorf("linebotapi: %d of %d chunks failed: %w", len(failed), len(r.Chunks), failed[0].Err)
+}
+
+// Broadcast sends content to every mid in to, splitting the list into chunks
+// the API accepts and sending them concurrently.
+func (c *Client) Broadcast(ctx context.Context, to []string, content *MessageContent, opts *BroadcastOptions) *BroadcastReport {
+    return broadcast(ctx, to, opts, func(ctx context.Context, chunk []string) error {
+        return c.SendMessageContext(ctx, chunk, content)
+    })
+}
+
+// BroadcastMessages is the SendMessages counterpart of Broadcast.
+func (c *Client) BroadcastMessages(ctx context.Context, to []string, contents []*MessageContent, notified int, opts *BroadcastOptions) *BroadcastReport {
+    return broadcast(ctx, to, opts, func(ctx context.Context, chunk []string) error {
+        return c.SendMessagesContext(ctx, chunk, contents, notified)
+    })
+}
+
+func splitRecipients(to []string, size int) [][]string {
+    if size <= 0 || size > MaxRecipients {
+        size = MaxRecipients
+    }
+    var chunks [][]string
+    for len(to) > 0 {
+        n := size
+        if n > len(to) {
+            n = len(to)
+        }
+        chunks = append(chunks, to[:n:n])
+        to = to[n:]
+    }
+    return chunks
+}
+
+func broadcast(ctx context.Context, to []string, opts *BroadcastOptions, send func(ctx context.Context, chunk []string) error) *BroadcastReport {
+    var chunkSize int
+    if opts != nil {
+        chunkSize = opts.ChunkSize
+    }
+    return sendChunks(ctx, splitRecipients(to, chunkSize), opts, func(ctx context.Context, index int, chunk []string) error {
+        return send(ctx, chunk)
+    })
+}
+
+// sendChunks calls send for every chunk with opts.Concurrency workers and
+// reports the results by chunk index.
+func sendChunks(ctx context.Context, chunks [][]string, opts *BroadcastOptions, send func(ctx context.Context, index int, chunk []string) error) *BroadcastReport {
+    concurrency := 4
+    if opts != nil && opts.Concurrency > 0 {
+        concurrency = opts.Concurrency
+    }
+
+    report := &BroadcastReport{
+        Chunks: make([]ChunkResult, len(chunks)),
+    }
+    indexes := make(chan int)
+    var wg sync.WaitGroup
+    for i := 0; i < concurrency && i < len(chunks); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for index := range indexes {
+                err := ctx.Err()
+                if err == nil {
+                    err = send(ctx, index, chunks[index])
+                }
+                report.Chunks[index] = ChunkResult{
+                    Index: index,
+                    To: chunks[index],
+                    Err: err,
+                }
+            }
+        }()
+    }
+    for i := range chunks {
+        indexes <- i
+    }
+    close(indexes)
+    wg.Wait()
+    return report
+}
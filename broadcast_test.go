@@ -0,0 +1,87 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "sync"
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+)
+
+func Test_Broadcast_Chunks(t *testing.T) {
+    var mu sync.Mutex
+    received := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var event Event
+        err := json.NewDecoder(r.Body).Decode(&event)
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        if len(event.To) > MaxRecipients {
+            t.Errorf("too many recipients: %d", len(event.To))
+        }
+        for _, mid := range event.To {
+            if mid == "u200" {
+                w.WriteHeader(400)
+                fmt.Fprintf(w, `{"statusCode":"422","statusMessage":"invalid users"}`)
+                return
+            }
+        }
+        mu.Lock()
+        received += len(event.To)
+        mu.Unlock()
+        w.WriteHeader(200)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+
+    to := make([]string, 350)
+    for i := range to {
+        to[i] = fmt.Sprintf("u%d", i)
+    }
+    report := client.Broadcast(context.Background(), to, NewMessageText("message"), &BroadcastOptions{Concurrency: 2})
+    if len(report.Chunks) != 3 {
+        t.Errorf("excepted: 3, actual: %d", len(report.Chunks))
+        return
+    }
+    if len(report.Chunks[2].To) != 50 {
+        t.Errorf("excepted: 50, actual: %d", len(report.Chunks[2].To))
+    }
+    failed := report.Failed()
+    if len(failed) != 1 || failed[0].Index != 1 || !IsInvalidRecipient(failed[0].Err) {
+        t.Errorf("unexpected failures: %#v", failed)
+    }
+    if !IsInvalidRecipient(report.Err()) {
+        t.Errorf("unexpected error: %v", report.Err())
+    }
+    if received != 200 {
+        t.Errorf("excepted: 200, actual: %d", received)
+    }
+}
+
+func Test_Broadcast_Canceled(t *testing.T) {
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    sent := 0
+    report := broadcast(ctx, []string{"a", "b", "c"}, &BroadcastOptions{ChunkSize: 1}, func(ctx context.Context, chunk []string) error {
+        sent++
+        return nil
+    })
+    if sent != 0 || len(report.Failed()) != 3 {
+        t.Errorf("unexpected report: %#v", report)
+    }
+    if report.Err() == nil {
+        t.Error("err is nil")
+    }
+}
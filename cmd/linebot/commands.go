@@ -0,0 +1,278 @@
+package main
+
+import (
+    "io"
+    "os"
+    "flag"
+    "strings"
+    "context"
+    "io/ioutil"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+)
+
+type sendResult struct {
+    OK bool `json:"ok"`
+    To []string `json:"to"`
+    Messages int `json:"messages"`
+}
+
+// parseFlags parses args and returns a client built from the config flags.
+func parseFlags(fs *flag.FlagSet, args []string) (*linebotapi.Client, error) {
+    cf := addConfigFlags(fs)
+    err := fs.Parse(args)
+    if err != nil {
+        if err == flag.ErrHelp {
+            return nil, err
+        }
+        return nil, &usageError{err.Error()}
+    }
+    c, err := cf.load()
+    if err != nil {
+        return nil, usagef("%s", err)
+    }
+    return c.client(), nil
+}
+
+func splitList(s string) []string {
+    var list []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            list = append(list, item)
+        }
+    }
+    return list
+}
+
+// send parses the flags shared by the send commands and sends the message
+// built by build.
+func send(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer, build func() (*linebotapi.MessageContent, error)) error {
+    to := fs.String("to", "", "comma separated recipient mids")
+    client, err := parseFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    mids := splitList(*to)
+    if len(mids) == 0 {
+        return usagef("-to is required")
+    }
+    content, err := build()
+    if err != nil {
+        return err
+    }
+    err = client.SendMessageContext(ctx, mids, content)
+    if err != nil {
+        return err
+    }
+    return writeJSON(stdout, sendResult{OK: true, To: mids, Messages: 1})
+}
+
+func runSendText(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if fs.NArg() != 1 {
+            return nil, usagef("exactly one TEXT argument is required")
+        }
+        return linebotapi.NewMessageText(fs.Arg(0)), nil
+    })
+}
+
+func runSendImage(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    contentURL := fs.String("url", "", "original content URL")
+    previewURL := fs.String("preview", "", "preview image URL")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if *contentURL == "" || *previewURL == "" {
+            return nil, usagef("-url and -preview are required")
+        }
+        return linebotapi.NewMessageImage(*contentURL, *previewURL), nil
+    })
+}
+
+func runSendVideo(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    contentURL := fs.String("url", "", "original content URL")
+    previewURL := fs.String("preview", "", "preview image URL")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if *contentURL == "" || *previewURL == "" {
+            return nil, usagef("-url and -preview are required")
+        }
+        return linebotapi.NewMessageVideo(*contentURL, *previewURL), nil
+    })
+}
+
+func runSendAudio(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    contentURL := fs.String("url", "", "original content URL")
+    length := fs.Int("length", 0, "audio length in milliseconds")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if *contentURL == "" || *length <= 0 {
+            return nil, usagef("-url and -length are required")
+        }
+        return linebotapi.NewMessageAudio(*contentURL, *length), nil
+    })
+}
+
+func runSendLocation(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    text := fs.String("text", "", "message text (defaults to the title)")
+    title := fs.String("title", "", "location title")
+    lat := fs.Float64("lat", 0, "latitude")
+    long := fs.Float64("long", 0, "longitude")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if *title == "" {
+            return nil, usagef("-title is required")
+        }
+        if *text == "" {
+            *text = *title
+        }
+        return linebotapi.NewMessageLocation(*text, *title, *lat, *long), nil
+    })
+}
+
+func runSendContact(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    mid := fs.String("contact-mid", "", "mid of the shared user")
+    name := fs.String("name", "", "display name of the shared user")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if !linebotapi.ValidMid(*mid) {
+            return nil, usagef("-contact-mid must be a user mid")
+        }
+        return linebotapi.NewMessageContact(*mid, *name), nil
+    })
+}
+
+func runSendSticker(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    packageId := fs.String("package", "", "sticker package ID")
+    id := fs.String("id", "", "sticker ID")
+    version := fs.String("version", "", "sticker version")
+    return send(ctx, fs, args, stdout, func() (*linebotapi.MessageContent, error) {
+        if *packageId == "" || *id == "" {
+            return nil, usagef("-package and -id are required")
+        }
+        return linebotapi.NewMessageSticker(*packageId, *id, *version), nil
+    })
+}
+
+// messageSpec is one element of the JSON array read by send-multi.
+type messageSpec struct {
+    Type string `json:"type"`
+    Text string `json:"text"`
+    URL string `json:"url"`
+    Preview string `json:"preview"`
+    Length int `json:"length"`
+    Title string `json:"title"`
+    Latitude float64 `json:"latitude"`
+    Longitude float64 `json:"longitude"`
+    PackageId string `json:"packageId"`
+    Id string `json:"id"`
+    Version string `json:"version"`
+    Mid string `json:"mid"`
+    DisplayName string `json:"displayName"`
+}
+
+func (s *messageSpec) content() (*linebotapi.MessageContent, error) {
+    switch s.Type {
+    case "text":
+        return linebotapi.NewMessageText(s.Text), nil
+    case "image":
+        return linebotapi.NewMessageImage(s.URL, s.Preview), nil
+    case "video":
+        return linebotapi.NewMessageVideo(s.URL, s.Preview), nil
+    case "audio":
+        return linebotapi.NewMessageAudio(s.URL, s.Length), nil
+    case "location":
+        return linebotapi.NewMessageLocation(s.Text, s.Title, s.Latitude, s.Longitude), nil
+    case "sticker":
+        return linebotapi.NewMessageSticker(s.PackageId, s.Id, s.Version), nil
+    case "contact":
+        if !linebotapi.ValidMid(s.Mid) {
+            return nil, usagef("invalid contact mid '%s'", s.Mid)
+        }
+        return linebotapi.NewMessageContact(s.Mid, s.DisplayName), nil
+    }
+    return nil, usagef("unknown message type '%s'", s.Type)
+}
+
+func runSendMulti(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    to := fs.String("to", "", "comma separated recipient mids")
+    notified := fs.Int("notified", 0, "index of the message used for the notification")
+    client, err := parseFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    mids := splitList(*to)
+    if len(mids) == 0 {
+        return usagef("-to is required")
+    }
+    if fs.NArg() != 1 {
+        return usagef("exactly one FILE argument is required")
+    }
+    var b []byte
+    if fs.Arg(0) == "-" {
+        b, err = ioutil.ReadAll(os.Stdin)
+    } else {
+        b, err = ioutil.ReadFile(fs.Arg(0))
+    }
+    if err != nil {
+        return err
+    }
+    var specs []messageSpec
+    err = json.Unmarshal(b, &specs)
+    if err != nil {
+        return usagef("invalid message file: %s", err)
+    }
+    contents := make([]*linebotapi.MessageContent, len(specs))
+    for i := range specs {
+        contents[i], err = specs[i].content()
+        if err != nil {
+            return err
+        }
+    }
+    err = client.SendMessagesContext(ctx, mids, contents, *notified)
+    if err != nil {
+        return err
+    }
+    return writeJSON(stdout, sendResult{OK: true, To: mids, Messages: len(contents)})
+}
+
+func runProfiles(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    client, err := parseFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    var mids []string
+    for _, arg := range fs.Args() {
+        mids = append(mids, splitList(arg)...)
+    }
+    if len(mids) == 0 {
+        return usagef("at least one MID is required")
+    }
+    contacts, err := client.GetUserProfilesContext(ctx, mids)
+    if err != nil {
+        return err
+    }
+    return writeJSON(stdout, contacts)
+}
+
+func runDownloadContent(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    output := fs.String("o", "", "output file path")
+    maxBytes := fs.Int64("max", 0, "maximum content size in bytes (0 for no limit)")
+    preview := fs.Bool("preview", false, "download the preview image instead")
+    client, err := parseFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    if *output == "" || fs.NArg() != 1 {
+        return usagef("-o and exactly one MESSAGE_ID argument are required")
+    }
+    download := client.DownloadMessageContentToFile
+    if *preview {
+        download = client.DownloadMessagePreviewContentToFile
+    }
+    info, err := download(ctx, fs.Arg(0), *output, *maxBytes)
+    if err != nil {
+        return err
+    }
+    return writeJSON(stdout, map[string]interface{}{
+        "path": *output,
+        "contentType": info.ContentType,
+        "contentLength": info.ContentLength,
+        "bytes": info.Written,
+    })
+}
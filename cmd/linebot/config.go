@@ -0,0 +1,118 @@
+package main
+
+import (
+    "os"
+    "flag"
+    "errors"
+    "strconv"
+    "io/ioutil"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+)
+
+// config holds the channel credential and API endpoint. Values are taken
+// from flags, then LINEBOT_* environment variables, then the JSON config
+// file given by -config or LINEBOT_CONFIG.
+type config struct {
+    ChannelId int `json:"channelId"`
+    ChannelSecret string `json:"channelSecret"`
+    Mid string `json:"mid"`
+    BaseURL string `json:"baseURL"`
+}
+
+type configFlags struct {
+    path string
+    channelId int
+    channelSecret string
+    mid string
+    baseURL string
+}
+
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+    f := &configFlags{}
+    fs.StringVar(&f.path, "config", "", "JSON config file (env LINEBOT_CONFIG)")
+    fs.IntVar(&f.channelId, "channel-id", 0, "channel ID (env LINEBOT_CHANNEL_ID)")
+    fs.StringVar(&f.channelSecret, "channel-secret", "", "channel secret (env LINEBOT_CHANNEL_SECRET)")
+    fs.StringVar(&f.mid, "mid", "", "channel MID (env LINEBOT_MID)")
+    fs.StringVar(&f.baseURL, "base-url", "", "API base URL (env LINEBOT_BASE_URL)")
+    return f
+}
+
+// load returns the config for commands that call the API.
+func (f *configFlags) load() (*config, error) {
+    c, err := f.loadPartial()
+    if err != nil {
+        return nil, err
+    }
+    if c.ChannelId == 0 || c.ChannelSecret == "" || c.Mid == "" {
+        return nil, errors.New("channel ID, channel secret and MID are required")
+    }
+    return c, nil
+}
+
+// loadPartial returns the config without checking for required values.
+func (f *configFlags) loadPartial() (*config, error) {
+    c := &config{}
+    path := f.path
+    if path == "" {
+        path = os.Getenv("LINEBOT_CONFIG")
+    }
+    if path != "" {
+        b, err := ioutil.ReadFile(path)
+        if err != nil {
+            return nil, err
+        }
+        err = json.Unmarshal(b, c)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    if v := os.Getenv("LINEBOT_CHANNEL_ID"); v != "" {
+        id, err := strconv.Atoi(v)
+        if err != nil {
+            return nil, errors.New("LINEBOT_CHANNEL_ID is not a number")
+        }
+        c.ChannelId = id
+    }
+    if v := os.Getenv("LINEBOT_CHANNEL_SECRET"); v != "" {
+        c.ChannelSecret = v
+    }
+    if v := os.Getenv("LINEBOT_MID"); v != "" {
+        c.Mid = v
+    }
+    if v := os.Getenv("LINEBOT_BASE_URL"); v != "" {
+        c.BaseURL = v
+    }
+
+    if f.channelId != 0 {
+        c.ChannelId = f.channelId
+    }
+    if f.channelSecret != "" {
+        c.ChannelSecret = f.channelSecret
+    }
+    if f.mid != "" {
+        c.Mid = f.mid
+    }
+    if f.baseURL != "" {
+        c.BaseURL = f.baseURL
+    }
+    return c, nil
+}
+
+func (c *config) credential() *linebotapi.Credential {
+    return &linebotapi.Credential{
+        ChannelId: c.ChannelId,
+        ChannelSecret: c.ChannelSecret,
+        Mid: c.Mid,
+    }
+}
+
+func (c *config) client() *linebotapi.Client {
+    client := linebotapi.NewClient(c.credential())
+    if c.BaseURL != "" {
+        client.BaseURL = c.BaseURL
+    }
+    return client
+}
@@ -0,0 +1,165 @@
+// Command linebot sends messages, looks up profiles and downloads message
+// content through the LINE Bot API.
+//
+//     linebot send-text -to u0123,u4567 "Hello!"
+//     linebot profiles u0123 u4567
+//     linebot download-content -o image.jpg 1234567890
+//     linebot webhook-send -url http://localhost:8080/callback callback.json
+//     linebot webhook-verify request.txt
+//
+// Results are written to stdout as JSON. Errors are written to stderr as
+// JSON and the exit status tells the kind of failure:
+//
+//     0  success
+//     1  other error
+//     2  invalid arguments
+//     3  authentication failed
+//     4  rate limited
+//     5  invalid recipient
+//     6  other API error
+//     7  API server error
+package main
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "flag"
+    "sort"
+    "errors"
+    "context"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+)
+
+const (
+    exitOK = iota
+    exitError
+    exitUsage
+    exitAuth
+    exitRateLimited
+    exitInvalidRecipient
+    exitAPIError
+    exitServerError
+)
+
+type command struct {
+    usage string
+    run func(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+    "send-text": {"-to MIDS TEXT", runSendText},
+    "send-image": {"-to MIDS -url URL -preview URL", runSendImage},
+    "send-video": {"-to MIDS -url URL -preview URL", runSendVideo},
+    "send-audio": {"-to MIDS -url URL -length MILLISECONDS", runSendAudio},
+    "send-location": {"-to MIDS -title TITLE -lat LAT -long LONG [-text TEXT]", runSendLocation},
+    "send-sticker": {"-to MIDS -package ID -id ID [-version VER]", runSendSticker},
+    "send-contact": {"-to MIDS -contact-mid MID [-name NAME]", runSendContact},
+    "send-multi": {"-to MIDS [-notified N] FILE|-", runSendMulti},
+    "profiles": {"MID...", runProfiles},
+    "download-content": {"-o PATH [-max BYTES] [-preview] MESSAGE_ID", runDownloadContent},
+    "webhook-send": {"-url URL FILE|-", runWebhookSend},
+    "webhook-verify": {"FILE|-", runWebhookVerify},
+}
+
+// usageError is returned for invalid arguments.
+type usageError struct {
+    message string
+}
+func (e *usageError) Error() string {
+    return e.message
+}
+
+func usagef(format string, args ...interface{}) error {
+    return &usageError{fmt.Sprintf(format, args...)}
+}
+
+func main() {
+    os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func printUsage(w io.Writer) {
+    names := make([]string, 0, len(commands))
+    for name := range commands {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    fmt.Fprintln(w, "usage: linebot COMMAND [flags] [args]")
+    fmt.Fprintln(w, "commands:")
+    for _, name := range names {
+        fmt.Fprintf(w, "  %s %s\n", name, commands[name].usage)
+    }
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+    if len(args) == 0 {
+        printUsage(stderr)
+        return exitUsage
+    }
+    cmd, exists := commands[args[0]]
+    if !exists {
+        printUsage(stderr)
+        return exitUsage
+    }
+    fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+    fs.SetOutput(stderr)
+    fs.Usage = func() {
+        fmt.Fprintf(stderr, "usage: linebot %s %s\n", args[0], cmd.usage)
+        fs.PrintDefaults()
+    }
+    err := cmd.run(ctx, fs, args[1:], stdout)
+    if err == flag.ErrHelp {
+        return exitUsage
+    }
+    if err != nil {
+        writeError(stderr, err)
+        if _, ok := err.(*usageError); ok {
+            fs.Usage()
+        }
+        return exitCode(err)
+    }
+    return exitOK
+}
+
+func exitCode(err error) int {
+    var usageErr *usageError
+    if errors.As(err, &usageErr) {
+        return exitUsage
+    }
+    var apiErr *linebotapi.APIError
+    if !errors.As(err, &apiErr) {
+        return exitError
+    }
+    switch {
+    case linebotapi.IsAuthError(err):
+        return exitAuth
+    case linebotapi.IsRateLimited(err):
+        return exitRateLimited
+    case linebotapi.IsInvalidRecipient(err):
+        return exitInvalidRecipient
+    case apiErr.HTTPStatus >= 500:
+        return exitServerError
+    }
+    return exitAPIError
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+    encoder := json.NewEncoder(w)
+    encoder.SetIndent("", "  ")
+    return encoder.Encode(v)
+}
+
+func writeError(w io.Writer, err error) {
+    output := map[string]interface{}{
+        "error": err.Error(),
+    }
+    var apiErr *linebotapi.APIError
+    if errors.As(err, &apiErr) {
+        output["httpStatus"] = apiErr.HTTPStatus
+        output["statusCode"] = apiErr.StatusCode
+        output["statusMessage"] = apiErr.StatusMessage
+    }
+    writeJSON(w, output)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+    "testing"
+
+    "os"
+    "bytes"
+    "context"
+    "strconv"
+    "io/ioutil"
+    "path/filepath"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+    "github.com/mokejp/linebotapi/linebotapitest"
+)
+
+func setCredentialEnv(t *testing.T, server *linebotapitest.Server) {
+    t.Setenv("LINEBOT_CHANNEL_ID", strconv.Itoa(server.Credential.ChannelId))
+    t.Setenv("LINEBOT_CHANNEL_SECRET", server.Credential.ChannelSecret)
+    t.Setenv("LINEBOT_MID", server.Credential.Mid)
+    t.Setenv("LINEBOT_BASE_URL", server.URL)
+}
+
+func Test_Run_SendText(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    setCredentialEnv(t, server)
+
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"send-text", "-to", "u1,u2", "Hello"}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+        return
+    }
+    var result sendResult
+    err := json.Unmarshal(stdout.Bytes(), &result)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if !result.OK || len(result.To) != 2 {
+        t.Errorf("unexpected result: %#v", result)
+    }
+    events := server.Events()
+    if len(events) != 1 || events[0].RawContent["text"] != "Hello" {
+        t.Errorf("unexpected events: %#v", events)
+    }
+}
+
+func Test_Run_SendMulti(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    setCredentialEnv(t, server)
+
+    path := filepath.Join(t.TempDir(), "messages.json")
+    ioutil.WriteFile(path, []byte(`[{"type":"text","text":"Hi"},{"type":"sticker","packageId":"1","id":"2","version":"100"},{"type":"contact","mid":"u0123456789abcdef0123456789abcdef","displayName":"Brown"}]`), 0600)
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"send-multi", "-to", "u1", path}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+        return
+    }
+    messages, _ := server.Events()[0].RawContent["messages"].([]interface{})
+    if len(messages) != 3 {
+        t.Errorf("excepted: 3, actual: %d", len(messages))
+    }
+}
+
+func Test_Run_SendContact(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    setCredentialEnv(t, server)
+
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"send-contact", "-to", "u1", "-contact-mid", "u0123456789abcdef0123456789abcdef", "-name", "Brown"}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+        return
+    }
+    events := server.Events()
+    if len(events) != 1 {
+        t.Errorf("excepted: 1, actual: %d", len(events))
+        return
+    }
+    metadata, _ := events[0].RawContent["contentMetadata"].(map[string]interface{})
+    if metadata["mid"] != "u0123456789abcdef0123456789abcdef" || metadata["displayName"] != "Brown" {
+        t.Errorf("unexpected content: %#v", events[0].RawContent)
+    }
+
+    stdout.Reset()
+    stderr.Reset()
+    code = run(context.Background(), []string{"send-contact", "-to", "u1", "-contact-mid", "u0123"}, &stdout, &stderr)
+    if code != exitUsage {
+        t.Errorf("excepted: %d, actual: %d", exitUsage, code)
+    }
+}
+
+func Test_Run_ConfigFile(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.AddProfile(linebotapi.Contact{Mid: "u1", DisplayName: "one"})
+
+    path := filepath.Join(t.TempDir(), "linebot.json")
+    b, _ := json.Marshal(config{
+        ChannelId: server.Credential.ChannelId,
+        ChannelSecret: "wrong",
+        Mid: server.Credential.Mid,
+        BaseURL: server.URL,
+    })
+    ioutil.WriteFile(path, b, 0600)
+
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"profiles", "-config", path, "u1"}, &stdout, &stderr)
+    if code != exitAuth {
+        t.Errorf("excepted: %d, actual: %d", exitAuth, code)
+    }
+
+    // flags take precedence over the config file
+    stdout.Reset()
+    code = run(context.Background(), []string{"profiles", "-config", path, "-channel-secret", server.Credential.ChannelSecret, "u1"}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+        return
+    }
+    var contacts linebotapi.Contacts
+    json.Unmarshal(stdout.Bytes(), &contacts)
+    if len(contacts.Contacts) != 1 || contacts.Contacts[0].DisplayName != "one" {
+        t.Errorf("unexpected output: %s", stdout.String())
+    }
+}
+
+func Test_Run_DownloadContent(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("jpeg"))
+    setCredentialEnv(t, server)
+
+    path := filepath.Join(t.TempDir(), "image.jpg")
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"download-content", "-o", path, "100"}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+        return
+    }
+    b, err := ioutil.ReadFile(path)
+    if err != nil || string(b) != "jpeg" {
+        t.Errorf("unexpected file: %q %v", b, err)
+    }
+
+    code = run(context.Background(), []string{"download-content", "-o", path + ".missing", "404"}, &stdout, &stderr)
+    if code != exitAPIError {
+        t.Errorf("excepted: %d, actual: %d", exitAPIError, code)
+    }
+    if _, err := os.Stat(path + ".missing"); !os.IsNotExist(err) {
+        t.Errorf("excepted: no file, actual: %v", err)
+    }
+}
+
+func Test_ExitCode(t *testing.T) {
+    tests := []struct {
+        err error
+        code int
+    }{
+        {usagef("bad"), exitUsage},
+        {&linebotapi.APIError{HTTPStatus: 401}, exitAuth},
+        {&linebotapi.APIError{HTTPStatus: 429}, exitRateLimited},
+        {&linebotapi.APIError{HTTPStatus: 400, StatusCode: "422"}, exitInvalidRecipient},
+        {&linebotapi.APIError{HTTPStatus: 503}, exitServerError},
+        {&linebotapi.APIError{HTTPStatus: 400}, exitAPIError},
+        {os.ErrNotExist, exitError},
+    }
+    for _, test := range tests {
+        if code := exitCode(test.err); code != test.code {
+            t.Errorf("%v: excepted: %d, actual: %d", test.err, test.code, code)
+        }
+    }
+}
+
+func Test_Run_Usage(t *testing.T) {
+    var stdout, stderr bytes.Buffer
+    if code := run(context.Background(), nil, &stdout, &stderr); code != exitUsage {
+        t.Errorf("excepted: %d, actual: %d", exitUsage, code)
+    }
+    t.Setenv("LINEBOT_CHANNEL_ID", "1")
+    t.Setenv("LINEBOT_CHANNEL_SECRET", "secret")
+    t.Setenv("LINEBOT_MID", "u1")
+    if code := run(context.Background(), []string{"send-text", "Hello"}, &stdout, &stderr); code != exitUsage {
+        t.Errorf("excepted: %d, actual: %d", exitUsage, code)
+    }
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+    "io"
+    "os"
+    "flag"
+    "bufio"
+    "bytes"
+    "errors"
+    "context"
+    "net/http"
+    "io/ioutil"
+)
+
+// parseWebhookFlags parses args for the webhook commands, which only need
+// the channel secret, and returns the config and the content of the FILE
+// argument.
+func parseWebhookFlags(fs *flag.FlagSet, args []string) (*config, []byte, error) {
+    cf := addConfigFlags(fs)
+    err := fs.Parse(args)
+    if err != nil {
+        if err == flag.ErrHelp {
+            return nil, nil, err
+        }
+        return nil, nil, &usageError{err.Error()}
+    }
+    c, err := cf.loadPartial()
+    if err != nil {
+        return nil, nil, usagef("%s", err)
+    }
+    if c.ChannelSecret == "" {
+        return nil, nil, usagef("channel secret is required")
+    }
+    if fs.NArg() != 1 {
+        return nil, nil, usagef("exactly one FILE argument is required")
+    }
+    var b []byte
+    if fs.Arg(0) == "-" {
+        b, err = ioutil.ReadAll(os.Stdin)
+    } else {
+        b, err = ioutil.ReadFile(fs.Arg(0))
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return c, b, nil
+}
+
+// runWebhookSend signs a saved callback body and POSTs it to a bot.
+func runWebhookSend(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    target := fs.String("url", "", "callback URL of the bot")
+    c, body, err := parseWebhookFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    if *target == "" {
+        return usagef("-url is required")
+    }
+    req, err := http.NewRequestWithContext(ctx, "POST", *target, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+    req.Header.Set("X-LINE-ChannelSignature", c.credential().Sign(body))
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    respBody, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return err
+    }
+    err = writeJSON(stdout, map[string]interface{}{
+        "status": resp.StatusCode,
+        "body": string(respBody),
+    })
+    if err != nil {
+        return err
+    }
+    if resp.StatusCode / 100 != 2 {
+        return errors.New("bot responded " + resp.Status)
+    }
+    return nil
+}
+
+// runWebhookVerify checks the signature of a request dump as written by
+// httputil.DumpRequest.
+func runWebhookVerify(ctx context.Context, fs *flag.FlagSet, args []string, stdout io.Writer) error {
+    c, dump, err := parseWebhookFlags(fs, args)
+    if err != nil {
+        return err
+    }
+    req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(dump)))
+    if err != nil {
+        return usagef("invalid request dump: %s", err)
+    }
+    body, err := ioutil.ReadAll(req.Body)
+    if err != nil {
+        return err
+    }
+    err = c.credential().VerifySignature(body, req.Header.Get("X-LINE-ChannelSignature"))
+    result := map[string]interface{}{
+        "valid": err == nil,
+    }
+    if err != nil {
+        result["error"] = err.Error()
+    }
+    if werr := writeJSON(stdout, result); werr != nil {
+        return werr
+    }
+    return err
+}
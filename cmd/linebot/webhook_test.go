@@ -0,0 +1,87 @@
+package main
+
+import (
+    "testing"
+
+    "bytes"
+    "context"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "net/http/httputil"
+    "path/filepath"
+
+    "github.com/mokejp/linebotapi"
+    "github.com/mokejp/linebotapi/linebotapitest"
+)
+
+func Test_Run_WebhookSend(t *testing.T) {
+    cred := &linebotapi.Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0123456789abcdef",
+        Mid: "u0123456789abcdef0123456789abcdef",
+    }
+    var texts []string
+    handler := linebotapi.NewWebhookHandler(cred)
+    handler.OnText(func(r *http.Request, m *linebotapi.TextMessage) error {
+        texts = append(texts, m.Text)
+        return nil
+    })
+    bot := httptest.NewServer(handler)
+    defer bot.Close()
+
+    body, err := linebotapitest.CallbackBody(cred, &linebotapi.TextMessage{Text: "replayed"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    path := filepath.Join(t.TempDir(), "callback.json")
+    ioutil.WriteFile(path, body, 0600)
+
+    var stdout, stderr bytes.Buffer
+    code := run(context.Background(), []string{"webhook-send", "-channel-secret", cred.ChannelSecret, "-url", bot.URL, path}, &stdout, &stderr)
+    if code != exitOK {
+        t.Errorf("excepted: 0, actual: %d (%s)", code, stderr.String())
+    }
+    if len(texts) != 1 || texts[0] != "replayed" {
+        t.Errorf("unexpected texts: %v", texts)
+    }
+
+    code = run(context.Background(), []string{"webhook-send", "-channel-secret", "wrong", "-url", bot.URL, path}, &stdout, &stderr)
+    if code != exitError {
+        t.Errorf("excepted: %d, actual: %d", exitError, code)
+    }
+}
+
+func Test_Run_WebhookVerify(t *testing.T) {
+    cred := &linebotapi.Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0123456789abcdef",
+        Mid: "u0123456789abcdef0123456789abcdef",
+    }
+    dir := t.TempDir()
+    for _, tamper := range []linebotapitest.Tamper{linebotapitest.TamperNone, linebotapitest.TamperBadSignature} {
+        req, err := linebotapitest.NewTamperedWebhookRequest(cred, "http://localhost/callback", tamper, &linebotapi.TextMessage{Text: "hello"})
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        dump, err := httputil.DumpRequestOut(req, true)
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        path := filepath.Join(dir, "request.txt")
+        ioutil.WriteFile(path, dump, 0600)
+
+        excepted := exitOK
+        if tamper != linebotapitest.TamperNone {
+            excepted = exitError
+        }
+        var stdout, stderr bytes.Buffer
+        code := run(context.Background(), []string{"webhook-verify", "-channel-secret", cred.ChannelSecret, path}, &stdout, &stderr)
+        if code != excepted {
+            t.Errorf("%d: excepted: %d, actual: %d (%s)", tamper, excepted, code, stderr.String())
+        }
+    }
+}
@@ -0,0 +1,63 @@
+package linebotapi
+
+import (
+    "fmt"
+    "context"
+    "strings"
+    "net/http"
+)
+
+// ValidMid reports whether mid has the form of a user mid: 'u' followed by
+// 32 lower case hex digits.
+func ValidMid(mid string) bool {
+    if len(mid) != 33 || mid[0] != 'u' {
+        return false
+    }
+    for _, c := range mid[1:] {
+        if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+            return false
+        }
+    }
+    return true
+}
+
+// ContactRejectedError is returned by SendContact when the server refuses the
+// contact itself: a 400 or 403 response whose status message is about the
+// contact. Reason is that status message. It wraps the APIError, so
+// IsAuthError and friends still apply. Other failures are returned as the
+// plain *APIError.
+type ContactRejectedError struct {
+    Mid string
+    Reason string
+    Err *APIError
+}
+func (e *ContactRejectedError) Error() string {
+    return fmt.Sprintf("linebotapi: contact message for '%s' rejected: %s: %v", e.Mid, e.Reason, e.Err)
+}
+func (e *ContactRejectedError) Unwrap() error {
+    return e.Err
+}
+
+func (c *Client) SendContact(to []string, mid, name string) error {
+    return c.SendContactContext(context.Background(), to, mid, name)
+}
+
+// SendContactContext shares the user mid as a contact named name. A mid that
+// is not valid according to ValidMid is rejected with a *FieldError before
+// anything is sent, whether or not the client validates messages.
+func (c *Client) SendContactContext(ctx context.Context, to []string, mid, name string) error {
+    content := NewMessageContact(mid, name)
+    if err := content.Validate(); err != nil {
+        return err
+    }
+    err := c.SendMessageContext(ctx, to, content)
+    e, ok := asAPIError(err)
+    if !ok {
+        return err
+    }
+    rejected := e.hasStatus(http.StatusBadRequest) || e.hasStatus(http.StatusForbidden)
+    if rejected && strings.Contains(strings.ToLower(e.StatusMessage), "contact") {
+        return &ContactRejectedError{Mid: mid, Reason: e.StatusMessage, Err: e}
+    }
+    return err
+}
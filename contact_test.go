@@ -0,0 +1,88 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+)
+
+func Test_ValidMid(t *testing.T) {
+    tests := []struct {
+        mid string
+        valid bool
+    }{
+        {"u0123456789abcdef0123456789abcdef", true},
+        {"u0123456789ABCDEF0123456789abcdef", false},
+        {"c0123456789abcdef0123456789abcdef", false},
+        {"u0123456789abcdef0123456789abcde", false},
+        {"", false},
+    }
+    for _, test := range tests {
+        if ValidMid(test.mid) != test.valid {
+            t.Errorf("%s: excepted: %v, actual: %v", test.mid, test.valid, !test.valid)
+        }
+    }
+}
+
+func Test_SendContact(t *testing.T) {
+    status := 200
+    message := ""
+    var sent map[string]interface{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var event Event
+        json.NewDecoder(r.Body).Decode(&event)
+        sent = event.RawContent
+        w.WriteHeader(status)
+        if status != 200 {
+            fmt.Fprintf(w, `{"statusCode":"%d","statusMessage":"%s"}`, status, message)
+        }
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    mid := "u0123456789abcdef0123456789abcdef"
+
+    err := client.SendContact([]string{"abcdefg"}, mid, "name")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if sent["contentType"] != float64(ContentTypeContact) || sent["contentMetadata"].(map[string]interface{})["mid"] != mid {
+        t.Errorf("unexpected content: %#v", sent)
+    }
+
+    sent = nil
+    err = client.SendContact([]string{"abcdefg"}, "invalid", "name")
+    if _, ok := err.(*FieldError); !ok || sent != nil {
+        t.Errorf("excepted: *FieldError, actual: %v", err)
+    }
+
+    status, message = 403, "contact messages are not allowed for this channel"
+    err = client.SendContact([]string{"abcdefg"}, mid, "name")
+    var rejected *ContactRejectedError
+    if !errors.As(err, &rejected) || rejected.Mid != mid || rejected.Reason != message {
+        t.Errorf("excepted: *ContactRejectedError, actual: %v", err)
+    }
+    if !IsAuthError(err) {
+        t.Errorf("APIError is not unwrapped: %v", err)
+    }
+
+    // failures unrelated to the contact are not wrapped
+    for _, status = range []int{400, 403, 500} {
+        message = "invalid request"
+        err = client.SendContact([]string{"abcdefg"}, mid, "name")
+        if _, ok := err.(*APIError); !ok {
+            t.Errorf("%d: excepted: *APIError, actual: %v", status, err)
+        }
+    }
+}
@@ -0,0 +1,113 @@
+package linebotapi
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "errors"
+    "context"
+    "io/ioutil"
+    "path/filepath"
+)
+
+var ErrContentTooLarge = errors.New("linebotapi: content exceeds size limit")
+
+// DownloadInfo describes a finished download. ContentLength is the value of
+// the Content-Length header, or -1 when it was not sent.
+type DownloadInfo struct {
+    ContentType string
+    ContentLength int64
+    Written int64
+}
+
+// DownloadMessageContent streams the content of a message to w. A maxBytes
+// of 0 or less does not limit the size; otherwise ErrContentTooLarge is
+// returned as soon as more than maxBytes have been read, so w never receives
+// more than maxBytes. The response body is always closed.
+func (c *Client) DownloadMessageContent(ctx context.Context, messageId string, w io.Writer, maxBytes int64) (*DownloadInfo, error) {
+    return c.download(ctx, fmt.Sprintf("/v1/bot/message/%s/content", messageId), w, maxBytes)
+}
+
+// DownloadMessageContentToFile is like DownloadMessageContent but writes to
+// the file at path. The file is written under a temporary name and only
+// renamed to path when the download succeeded.
+func (c *Client) DownloadMessageContentToFile(ctx context.Context, messageId, path string, maxBytes int64) (*DownloadInfo, error) {
+    return c.downloadToFile(ctx, fmt.Sprintf("/v1/bot/message/%s/content", messageId), path, maxBytes)
+}
+
+// DownloadMessagePreviewContent is like DownloadMessageContent for the
+// preview image of an image or video message.
+func (c *Client) DownloadMessagePreviewContent(ctx context.Context, messageId string, w io.Writer, maxBytes int64) (*DownloadInfo, error) {
+    return c.download(ctx, fmt.Sprintf("/v1/bot/message/%s/content/preview", messageId), w, maxBytes)
+}
+
+func (c *Client) DownloadMessagePreviewContentToFile(ctx context.Context, messageId, path string, maxBytes int64) (*DownloadInfo, error) {
+    return c.downloadToFile(ctx, fmt.Sprintf("/v1/bot/message/%s/content/preview", messageId), path, maxBytes)
+}
+
+func (c *Client) download(ctx context.Context, path string, w io.Writer, maxBytes int64) (*DownloadInfo, error) {
+    data, err := c.getContent(ctx, path)
+    if err != nil {
+        return nil, err
+    }
+    defer data.Reader.Close()
+
+    info := &DownloadInfo{
+        ContentType: data.ContentType,
+        ContentLength: data.ContentLength,
+    }
+    if maxBytes > 0 && data.ContentLength > maxBytes {
+        return info, ErrContentTooLarge
+    }
+    var reader io.Reader = data.Reader
+    if maxBytes > 0 {
+        reader = &sizeLimitedReader{r: data.Reader, remaining: maxBytes}
+    }
+    info.Written, err = io.Copy(w, reader)
+    if err != nil {
+        return info, err
+    }
+    return info, nil
+}
+
+// sizeLimitedReader fails with ErrContentTooLarge instead of stopping at the
+// limit like io.LimitReader.
+type sizeLimitedReader struct {
+    r io.Reader
+    remaining int64
+}
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+    if int64(len(p)) > l.remaining + 1 {
+        p = p[:l.remaining + 1]
+    }
+    n, err := l.r.Read(p)
+    if int64(n) > l.remaining {
+        n = int(l.remaining)
+        l.remaining = 0
+        return n, ErrContentTooLarge
+    }
+    l.remaining -= int64(n)
+    return n, err
+}
+
+func (c *Client) downloadToFile(ctx context.Context, path, filename string, maxBytes int64) (*DownloadInfo, error) {
+    f, err := ioutil.TempFile(filepath.Dir(filename), "." + filepath.Base(filename) + ".*")
+    if err != nil {
+        return nil, err
+    }
+    info, err := c.download(ctx, path, f, maxBytes)
+    if err == nil {
+        err = f.Chmod(0644)
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err == nil {
+        err = os.Rename(f.Name(), filename)
+    }
+    if err != nil {
+        os.Remove(f.Name())
+        return info, err
+    }
+    return info, nil
+}
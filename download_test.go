@@ -0,0 +1,159 @@
+package linebotapi_test
+
+import (
+    "testing"
+
+    "os"
+    "time"
+    "bytes"
+    "errors"
+    "context"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+
+    "github.com/mokejp/linebotapi"
+    "github.com/mokejp/linebotapi/linebotapitest"
+)
+
+func Test_DownloadMessageContent_Success(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("0123456789"))
+
+    var buf bytes.Buffer
+    info, err := server.APIClient().DownloadMessageContent(context.Background(), "100", &buf, 10)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if buf.String() != "0123456789" || info.Written != 10 || info.ContentLength != 10 || info.ContentType != "image/jpeg" {
+        t.Errorf("unexpected download: %q %#v", buf.String(), info)
+    }
+}
+
+func Test_DownloadMessageContent_TooLarge(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("0123456789"))
+
+    var buf bytes.Buffer
+    info, err := server.APIClient().DownloadMessageContent(context.Background(), "100", &buf, 9)
+    if err != linebotapi.ErrContentTooLarge {
+        t.Errorf("excepted: ErrContentTooLarge, actual: %v", err)
+    }
+    if buf.Len() != 0 || info.ContentLength != 10 {
+        t.Errorf("unexpected download: %q %#v", buf.String(), info)
+    }
+}
+
+func Test_DownloadMessageContentToFile(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "audio/x-m4a", []byte("0123456789"))
+    client := server.APIClient()
+    dir := t.TempDir()
+
+    path := filepath.Join(dir, "audio.m4a")
+    info, err := client.DownloadMessageContentToFile(context.Background(), "100", path, 0)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    b, err := ioutil.ReadFile(path)
+    if err != nil || string(b) != "0123456789" || info.Written != 10 {
+        t.Errorf("unexpected file: %q %v", b, err)
+    }
+
+    _, err = client.DownloadMessageContentToFile(context.Background(), "100", filepath.Join(dir, "large.m4a"), 5)
+    if err != linebotapi.ErrContentTooLarge {
+        t.Errorf("excepted: ErrContentTooLarge, actual: %v", err)
+    }
+    _, err = client.DownloadMessageContentToFile(context.Background(), "404", filepath.Join(dir, "missing.m4a"), 0)
+    var apiErr *linebotapi.APIError
+    if !errors.As(err, &apiErr) || apiErr.HTTPStatus != 404 {
+        t.Errorf("excepted: 404, actual: %v", err)
+    }
+    files, _ := ioutil.ReadDir(dir)
+    if len(files) != 1 {
+        t.Errorf("excepted: only audio.m4a, actual: %d files", len(files))
+    }
+    if _, err := os.Stat(filepath.Join(dir, "large.m4a")); !os.IsNotExist(err) {
+        t.Errorf("excepted: no file, actual: %v", err)
+    }
+}
+
+func Test_DownloadMessageContent_Canceled(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("0123456789"))
+    server.SetLatency(time.Second)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+    defer cancel()
+    _, err := server.APIClient().DownloadMessageContent(ctx, "100", ioutil.Discard, 0)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("excepted: context.DeadlineExceeded, actual: %v", err)
+    }
+}
+
+func Test_DownloadMessageContent_TooLargeChunked(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Content-Type", "video/mp4")
+        w.WriteHeader(200)
+        w.Write([]byte("01234"))
+        w.(http.Flusher).Flush()
+        w.Write([]byte("56789"))
+    }))
+    defer server.Close()
+
+    client := linebotapi.NewClient(&linebotapi.Credential{ChannelId: 1, ChannelSecret: "secret", Mid: "mid"})
+    client.BaseURL = server.URL
+    var buf bytes.Buffer
+    info, err := client.DownloadMessageContent(context.Background(), "100", &buf, 8)
+    if err != linebotapi.ErrContentTooLarge {
+        t.Errorf("excepted: ErrContentTooLarge, actual: %v", err)
+    }
+    if info.ContentLength != -1 || info.Written != 8 {
+        t.Errorf("unexpected download: %#v", info)
+    }
+}
+
+func Test_GetMessagePreviewContent(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("original"))
+    server.SetPreviewContent("100", "image/jpeg", []byte("preview"))
+    client := server.APIClient()
+
+    data, err := client.GetMessagePreviewContent(&linebotapi.EventContent{Id: "100"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    defer data.Reader.Close()
+    b, _ := ioutil.ReadAll(data.Reader)
+    if string(b) != "preview" || data.ContentType != "image/jpeg" || data.ContentLength != 7 {
+        t.Errorf("unexpected content: %q %#v", b, data)
+    }
+
+    var buf bytes.Buffer
+    _, err = client.DownloadMessagePreviewContent(context.Background(), "100", &buf, 3)
+    if err != linebotapi.ErrContentTooLarge {
+        t.Errorf("excepted: ErrContentTooLarge, actual: %v", err)
+    }
+    path := filepath.Join(t.TempDir(), "preview.jpg")
+    info, err := client.DownloadMessagePreviewContentToFile(context.Background(), "100", path, 0)
+    if err != nil || info.Written != 7 {
+        t.Errorf("unexpected download: %#v %v", info, err)
+    }
+    _, err = client.GetMessagePreviewContent(&linebotapi.EventContent{Id: "404"})
+    var apiErr *linebotapi.APIError
+    if !errors.As(err, &apiErr) || apiErr.HTTPStatus != 404 || apiErr.Path != "/v1/bot/message/404/content/preview" {
+        t.Errorf("excepted: 404, actual: %v", err)
+    }
+    if server.Requests("/v1/bot/message/{id}/content/preview") != 4 || server.Requests("/v1/bot/message/{id}/content") != 0 {
+        t.Errorf("unexpected requests")
+    }
+}
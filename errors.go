@@ -0,0 +1,86 @@
+package linebotapi
+
+import (
+    "fmt"
+    "errors"
+    "time"
+    "strconv"
+    "strings"
+    "net/http"
+)
+
+// APIError is returned when the Bot API server responds with a non-200
+// status. StatusCode and StatusMessage are taken from the JSON error body and
+// are empty when the body is not JSON.
+type APIError struct {
+    HTTPStatus int
+    StatusCode string
+    StatusMessage string
+    Path string
+    Body []byte
+    // RetryAfter is the delay requested by the Retry-After header, if any.
+    RetryAfter time.Duration
+}
+func (e *APIError) Error() string {
+    if e.StatusCode == "" && e.StatusMessage == "" {
+        return fmt.Sprintf("linebotapi: %s: %d %s", e.Path, e.HTTPStatus, http.StatusText(e.HTTPStatus))
+    }
+    return fmt.Sprintf("linebotapi: %s: %s: %s", e.Path, e.StatusCode, e.StatusMessage)
+}
+
+func asAPIError(err error) (*APIError, bool) {
+    var e *APIError
+    if errors.As(err, &e) {
+        return e, true
+    }
+    return nil, false
+}
+
+func (e *APIError) hasStatus(status int) bool {
+    return e.HTTPStatus == status || e.StatusCode == fmt.Sprint(status)
+}
+
+// IsRateLimited reports whether err is an APIError caused by exceeding the
+// API rate limit.
+func IsRateLimited(err error) bool {
+    e, ok := asAPIError(err)
+    return ok && e.hasStatus(http.StatusTooManyRequests)
+}
+
+// IsAuthError reports whether err is an APIError caused by rejected channel
+// credentials.
+func IsAuthError(err error) bool {
+    e, ok := asAPIError(err)
+    return ok && (e.hasStatus(http.StatusUnauthorized) || e.hasStatus(http.StatusForbidden))
+}
+
+// IsInvalidRecipient reports whether err is an APIError caused by a mid in
+// the To list that the channel cannot send to.
+func IsInvalidRecipient(err error) bool {
+    e, ok := asAPIError(err)
+    if !ok {
+        return false
+    }
+    message := strings.ToLower(e.StatusMessage)
+    return e.hasStatus(http.StatusUnprocessableEntity) ||
+        strings.Contains(message, "invalid user") ||
+        strings.Contains(message, "invalid mid")
+}
+
+func parseRetryAfter(value string) time.Duration {
+    if value == "" {
+        return 0
+    }
+    if seconds, err := strconv.Atoi(value); err == nil {
+        if seconds < 0 {
+            return 0
+        }
+        return time.Duration(seconds) * time.Second
+    }
+    if t, err := http.ParseTime(value); err == nil {
+        if d := time.Until(t); d > 0 {
+            return d
+        }
+    }
+    return 0
+}
@@ -0,0 +1,108 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "errors"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+)
+
+func Test_APIError_JSONBody(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(429)
+        fmt.Fprintf(w, `{"statusCode":"429","statusMessage":"rate limit exceeded"}`)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    err := client.SendMessage([]string{"test"}, NewMessageText("message"))
+    var apiErr *APIError
+    if !errors.As(err, &apiErr) {
+        t.Errorf("excepted: *APIError, actual: %#v", err)
+        return
+    }
+    if apiErr.HTTPStatus != 429 || apiErr.StatusCode != "429" || apiErr.StatusMessage != "rate limit exceeded" || apiErr.Path != "/v1/events" {
+        t.Errorf("unexpected error: %#v", apiErr)
+    }
+    if !IsRateLimited(err) || IsAuthError(err) || IsInvalidRecipient(err) {
+        t.Errorf("unexpected classification: %s", err)
+    }
+}
+
+func Test_APIError_PlainBody(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(401)
+        fmt.Fprintf(w, `Unauthorized`)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    _, err := client.GetUserProfiles([]string{"u0047556f2e40dba2456887320ba7c76d"})
+    var apiErr *APIError
+    if !errors.As(err, &apiErr) {
+        t.Errorf("excepted: *APIError, actual: %#v", err)
+        return
+    }
+    if apiErr.HTTPStatus != 401 || apiErr.StatusCode != "" || string(apiErr.Body) != "Unauthorized" || apiErr.Path != "/v1/profiles" {
+        t.Errorf("unexpected error: %#v", apiErr)
+    }
+    if !IsAuthError(err) || IsRateLimited(err) {
+        t.Errorf("unexpected classification: %s", err)
+    }
+}
+
+func Test_IsInvalidRecipient(t *testing.T) {
+    err := fmt.Errorf("send: %w", &APIError{HTTPStatus: 400, StatusCode: "422", StatusMessage: "invalid users"})
+    if !IsInvalidRecipient(err) {
+        t.Error("excepted: true, actual: false")
+    }
+    if IsInvalidRecipient(errors.New("422")) {
+        t.Error("excepted: false, actual: true")
+    }
+}
+
+type failingReader struct {
+    data []byte
+}
+func (r *failingReader) Read(p []byte) (int, error) {
+    if len(r.data) == 0 {
+        return 0, errors.New("connection reset")
+    }
+    n := copy(p, r.data)
+    r.data = r.data[n:]
+    return n, nil
+}
+
+func Test_APIError_BodyReadFails(t *testing.T) {
+    client := NewClient(&Credential{ChannelId: 1234567890})
+    req := httptest.NewRequest("GET", "https://trialbot-api.line.me/v1/profiles", nil)
+    err := client.handleError(&http.Response{
+        StatusCode: 503,
+        Header: http.Header{},
+        Body: ioutil.NopCloser(&failingReader{data: []byte(`{"statusCo`)}),
+        Request: req,
+    })
+    apiErr, ok := err.(*APIError)
+    if !ok {
+        t.Errorf("excepted: *APIError, actual: %#v", err)
+        return
+    }
+    if apiErr.HTTPStatus != 503 || string(apiErr.Body) != `{"statusCo` || apiErr.Path != "/v1/profiles" {
+        t.Errorf("unexpected error: %#v", apiErr)
+    }
+}
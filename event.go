@@ -0,0 +1,282 @@
+package linebotapi
+
+import (
+    "fmt"
+    "reflect"
+    "strconv"
+    "encoding/json"
+)
+
+// Content is implemented by every typed callback content. Use a type switch
+// to handle each shape:
+//
+//     switch m := event.Content.(type) {
+//     case *linebotapi.TextMessage:
+//     case *linebotapi.AddedOperation:
+//     }
+type Content interface {
+    Header() *ContentHeader
+}
+
+// ContentHeader holds the fields shared by every callback content.
+type ContentHeader struct {
+    Id string
+    From string
+    CreatedTime int64
+    To []string
+    ToType uint8
+}
+func (c *ContentHeader) Header() *ContentHeader {
+    return c
+}
+
+type TextMessage struct {
+    ContentHeader
+    Text string
+}
+
+type ImageMessage struct {
+    ContentHeader
+}
+
+type VideoMessage struct {
+    ContentHeader
+}
+
+type AudioMessage struct {
+    ContentHeader
+    AudioLength int
+}
+
+type LocationMessage struct {
+    ContentHeader
+    Text string
+    Title string
+    Latitude float64
+    Longitude float64
+}
+
+type StickerMessage struct {
+    ContentHeader
+    StickerId string
+    StickerPackageId string
+    StickerVersion string
+}
+
+type ContactMessage struct {
+    ContentHeader
+    Mid string
+    DisplayName string
+}
+
+type AddedOperation struct {
+    ContentHeader
+}
+
+type BlockedOperation struct {
+    ContentHeader
+}
+
+// UnknownContent is used for content and operation types this package does
+// not model yet.
+type UnknownContent struct {
+    ContentHeader
+    IsOperation bool
+    IsMessage bool
+    OpType uint8
+    ContentType uint8
+}
+
+// FieldError is returned when a callback payload lacks a required field or
+// carries a value of an unexpected JSON type, and when an outbound message
+// fails validation.
+type FieldError struct {
+    Field string
+    Expected string
+    Actual string
+}
+func (e *FieldError) Error() string {
+    if e.Actual == "" {
+        return fmt.Sprintf("linebotapi: missing field '%s'", e.Field)
+    }
+    return fmt.Sprintf("linebotapi: invalid field '%s': expected %s, got %s", e.Field, e.Expected, e.Actual)
+}
+
+type locationPayload struct {
+    Title *string `json:"title"`
+    Latitude *float64 `json:"latitude"`
+    Longitude *float64 `json:"longitude"`
+}
+
+type contentPayload struct {
+    Id *string `json:"id"`
+    From *string `json:"from"`
+    CreatedTime *int64 `json:"createdTime"`
+    To *[]string `json:"to"`
+    ToType *uint8 `json:"toType"`
+    OpType *uint8 `json:"opType"`
+    ContentType *uint8 `json:"contentType"`
+    Text *string `json:"text"`
+    Location *locationPayload `json:"location"`
+    // values are not always strings, e.g. EMTVER of emoji texts
+    ContentMetadata map[string]interface{} `json:"contentMetadata"`
+}
+
+func jsonKindName(t reflect.Type) string {
+    switch t.Kind() {
+    case reflect.String:
+        return "string"
+    case reflect.Bool:
+        return "boolean"
+    case reflect.Slice, reflect.Array:
+        return "array"
+    case reflect.Map, reflect.Struct:
+        return "object"
+    case reflect.Ptr:
+        return jsonKindName(t.Elem())
+    }
+    return "number"
+}
+
+func jsonTypeName(v interface{}) string {
+    switch v.(type) {
+    case nil:
+        return "null"
+    case string:
+        return "string"
+    case float64:
+        return "number"
+    case bool:
+        return "boolean"
+    case []interface{}:
+        return "array"
+    case map[string]interface{}:
+        return "object"
+    }
+    return fmt.Sprintf("%T", v)
+}
+
+func missingField(name string) error {
+    return &FieldError{Field: "content." + name}
+}
+
+func metadataField(metadata map[string]interface{}, key string) (string, error) {
+    v, exists := metadata[key]
+    if !exists {
+        return "", missingField("contentMetadata." + key)
+    }
+    s, ok := v.(string)
+    if !ok {
+        return "", &FieldError{Field: "content.contentMetadata." + key, Expected: "string", Actual: jsonTypeName(v)}
+    }
+    return s, nil
+}
+
+func decodeContent(raw []byte) (Content, error) {
+    var p contentPayload
+    if err := json.Unmarshal(raw, &p); err != nil {
+        if e, ok := err.(*json.UnmarshalTypeError); ok {
+            return nil, &FieldError{Field: "content." + e.Field, Expected: jsonKindName(e.Type), Actual: e.Value}
+        }
+        return nil, err
+    }
+
+    switch {
+    case p.Id == nil:
+        return nil, missingField("id")
+    case p.From == nil:
+        return nil, missingField("from")
+    case p.CreatedTime == nil:
+        return nil, missingField("createdTime")
+    case p.To == nil:
+        return nil, missingField("to")
+    case p.ToType == nil:
+        return nil, missingField("toType")
+    }
+    header := ContentHeader{
+        Id: *p.Id,
+        From: *p.From,
+        CreatedTime: *p.CreatedTime,
+        To: *p.To,
+        ToType: *p.ToType,
+    }
+
+    if p.OpType != nil {
+        switch *p.OpType {
+        case OpTypeAdded:
+            return &AddedOperation{ContentHeader: header}, nil
+        case OpTypeBlocked:
+            return &BlockedOperation{ContentHeader: header}, nil
+        }
+        return &UnknownContent{ContentHeader: header, IsOperation: true, OpType: *p.OpType}, nil
+    }
+    if p.ContentType == nil {
+        return &UnknownContent{ContentHeader: header}, nil
+    }
+
+    switch *p.ContentType {
+    case ContentTypeText:
+        if p.Text == nil {
+            return nil, missingField("text")
+        }
+        return &TextMessage{ContentHeader: header, Text: *p.Text}, nil
+    case ContentTypeImage:
+        return &ImageMessage{ContentHeader: header}, nil
+    case ContentTypeVideo:
+        return &VideoMessage{ContentHeader: header}, nil
+    case ContentTypeAudio:
+        audlen, err := metadataField(p.ContentMetadata, "AUDLEN")
+        if err != nil {
+            return nil, err
+        }
+        length, err := strconv.Atoi(audlen)
+        if err != nil {
+            return nil, &FieldError{Field: "content.contentMetadata.AUDLEN", Expected: "integer string", Actual: strconv.Quote(audlen)}
+        }
+        return &AudioMessage{ContentHeader: header, AudioLength: length}, nil
+    case ContentTypeLocation:
+        switch {
+        case p.Text == nil:
+            return nil, missingField("text")
+        case p.Location == nil:
+            return nil, missingField("location")
+        case p.Location.Title == nil:
+            return nil, missingField("location.title")
+        case p.Location.Latitude == nil:
+            return nil, missingField("location.latitude")
+        case p.Location.Longitude == nil:
+            return nil, missingField("location.longitude")
+        }
+        return &LocationMessage{
+            ContentHeader: header,
+            Text: *p.Text,
+            Title: *p.Location.Title,
+            Latitude: *p.Location.Latitude,
+            Longitude: *p.Location.Longitude,
+        }, nil
+    case ContentTypeSticker:
+        m := &StickerMessage{ContentHeader: header}
+        var err error
+        if m.StickerId, err = metadataField(p.ContentMetadata, "STKID"); err != nil {
+            return nil, err
+        }
+        if m.StickerPackageId, err = metadataField(p.ContentMetadata, "STKPKGID"); err != nil {
+            return nil, err
+        }
+        if m.StickerVersion, err = metadataField(p.ContentMetadata, "STKVER"); err != nil {
+            return nil, err
+        }
+        return m, nil
+    case ContentTypeContact:
+        m := &ContactMessage{ContentHeader: header}
+        var err error
+        if m.Mid, err = metadataField(p.ContentMetadata, "mid"); err != nil {
+            return nil, err
+        }
+        if m.DisplayName, err = metadataField(p.ContentMetadata, "displayName"); err != nil {
+            return nil, err
+        }
+        return m, nil
+    }
+    return &UnknownContent{ContentHeader: header, IsMessage: true, ContentType: *p.ContentType}, nil
+}
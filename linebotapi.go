@@ -5,8 +5,10 @@ import (
     "fmt"
     "bytes"
     "errors"
+    "context"
     "strconv"
     "strings"
+    "io/ioutil"
     "net/url"
     "net/http"
     "crypto/hmac"
@@ -23,6 +25,7 @@ const (
     ContentTypeLocation = 7
     ContentTypeSticker  = 8
     ContentTypeContact  = 10
+    ContentTypeRich     = 12
 )
 
 const (
@@ -40,6 +43,33 @@ type Credential struct {
     Mid string
 }
 
+// Sign returns the X-LINE-ChannelSignature value of a callback body: the
+// base64 encoded HMAC-SHA256 of body keyed with ChannelSecret.
+func (c *Credential) Sign(body []byte) string {
+    mac := hmac.New(sha256.New, []byte(c.ChannelSecret))
+    mac.Write(body)
+    return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks an X-LINE-ChannelSignature value against body. It
+// returns ErrSignatureNotFound for an empty signature and
+// ErrInvalidSignature when it does not match.
+func (c *Credential) VerifySignature(body []byte, signature string) error {
+    if signature == "" {
+        return ErrSignatureNotFound
+    }
+    expectedMAC, err := base64.StdEncoding.DecodeString(signature)
+    if err != nil {
+        return ErrInvalidSignature
+    }
+    mac := hmac.New(sha256.New, []byte(c.ChannelSecret))
+    mac.Write(body)
+    if !hmac.Equal(mac.Sum(nil), expectedMAC) {
+        return ErrInvalidSignature
+    }
+    return nil
+}
+
 type Event struct {
     Id string `json:"id,omitempty"`
     From string `json:"from,omitempty"`
@@ -48,32 +78,63 @@ type Event struct {
     ToChannel int `json:"toChannel,omitempty"`
     EventType string `json:"eventType,omitempty"`
     RawContent map[string]interface{} `json:"content"`
-}
-func (c *Event) GetEventContent() *EventContent {
-    toArray := c.RawContent["to"].([]interface {})
-    to := make([]string, len(toArray))
-    for i, item := range toArray {
-        to[i] = item.(string)
-    }
+    Content Content `json:"-"`
+    // Err is set by ParseRequest when the event could not be decoded. Content
+    // is nil then; the other events of the callback are not affected.
+    Err error `json:"-"`
+}
+// GetEventContent returns the untyped view of the event content. Events
+// returned by ParseRequest are already decoded into Content; for other events
+// the content is decoded from RawContent. The event is not modified.
+func (c *Event) GetEventContent() (*EventContent, error) {
+    if c.Err != nil {
+        return nil, c.Err
+    }
+    decoded := c.Content
+    if decoded == nil {
+        raw, err := json.Marshal(c.RawContent)
+        if err != nil {
+            return nil, err
+        }
+        decoded, err = decodeContent(raw)
+        if err != nil {
+            return nil, err
+        }
+    }
+    header := decoded.Header()
     content := &EventContent{
         Event: c,
-        Id: c.RawContent["id"].(string),
-        From: c.RawContent["from"].(string),
-        CreatedTime: int(c.RawContent["createdTime"].(float64)),
-        To: to,
-        ToType: uint8(c.RawContent["toType"].(float64)),
-    }
-    opType, exists := c.RawContent["opType"]
-    if exists {
-        content.OpType = uint8(opType.(float64))
-        content.IsOperation = true
-    }
-    contentType, exists := c.RawContent["contentType"]
-    if exists {
-        content.ContentType = uint8(contentType.(float64))
-        content.IsMessage = true
-    }
-    return content
+        Id: header.Id,
+        From: header.From,
+        CreatedTime: int(header.CreatedTime),
+        To: header.To,
+        ToType: header.ToType,
+        decoded: decoded,
+    }
+    switch m := decoded.(type) {
+    case *AddedOperation:
+        content.IsOperation, content.OpType = true, OpTypeAdded
+    case *BlockedOperation:
+        content.IsOperation, content.OpType = true, OpTypeBlocked
+    case *TextMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeText
+    case *ImageMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeImage
+    case *VideoMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeVideo
+    case *AudioMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeAudio
+    case *LocationMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeLocation
+    case *StickerMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeSticker
+    case *ContactMessage:
+        content.IsMessage, content.ContentType = true, ContentTypeContact
+    case *UnknownContent:
+        content.IsOperation, content.OpType = m.IsOperation, m.OpType
+        content.IsMessage, content.ContentType = m.IsMessage, m.ContentType
+    }
+    return content, nil
 }
 
 type Mapper interface {
@@ -97,73 +158,113 @@ type EventContent struct {
     IsMessage bool
     OpType uint8
     ContentType uint8
+
+    decoded Content
 }
-func (c *EventContent) GetMessageText() (*MessageText, error) {
-    if c.ContentType != ContentTypeText {
+func (c *EventContent) content(contentType uint8) (Content, error) {
+    if c.ContentType != contentType {
         return nil, errors.New("invalid contentType")
     }
+    if c.decoded == nil {
+        return nil, errors.New("linebotapi: event content was not created by GetEventContent")
+    }
+    return c.decoded, nil
+}
+
+func unexpectedContent(content Content) error {
+    return fmt.Errorf("linebotapi: unexpected content type %T", content)
+}
+func (c *EventContent) GetMessageText() (*MessageText, error) {
+    content, err := c.content(ContentTypeText)
+    if err != nil {
+        return nil, err
+    }
+    m, ok := content.(*TextMessage)
+    if !ok {
+        return nil, unexpectedContent(content)
+    }
     return &MessageText{
-        Text: c.Event.RawContent["text"].(string),
+        Text: m.Text,
     }, nil
 }
 func (c *EventContent) GetMessageImage() (*MessageImage, error) {
-    if c.ContentType != ContentTypeImage {
-        return nil, errors.New("invalid contentType")
+    content, err := c.content(ContentTypeImage)
+    if err != nil {
+        return nil, err
+    }
+    if _, ok := content.(*ImageMessage); !ok {
+        return nil, unexpectedContent(content)
     }
     return &MessageImage{
     }, nil
 }
 func (c *EventContent) GetMessageVideo() (*MessageVideo, error) {
-    if c.ContentType != ContentTypeVideo {
-        return nil, errors.New("invalid contentType")
+    content, err := c.content(ContentTypeVideo)
+    if err != nil {
+        return nil, err
+    }
+    if _, ok := content.(*VideoMessage); !ok {
+        return nil, unexpectedContent(content)
     }
     return &MessageVideo{
     }, nil
 }
 func (c *EventContent) GetMessageAudio() (*MessageAudio, error) {
-    if c.ContentType != ContentTypeAudio {
-        return nil, errors.New("invalid contentType")
-    }
-    metadata := c.Event.RawContent["contentMetadata"].(map[string]interface{})
-    length, err := strconv.Atoi(metadata["AUDLEN"].(string))
+    content, err := c.content(ContentTypeAudio)
     if err != nil {
         return nil, err
     }
+    m, ok := content.(*AudioMessage)
+    if !ok {
+        return nil, unexpectedContent(content)
+    }
     return &MessageAudio{
-        AudioLength: length,
+        AudioLength: m.AudioLength,
     }, nil
 }
 func (c *EventContent) GetMessageLocation() (*MessageLocation, error) {
-    if c.ContentType != ContentTypeLocation {
-        return nil, errors.New("invalid contentType")
+    content, err := c.content(ContentTypeLocation)
+    if err != nil {
+        return nil, err
+    }
+    m, ok := content.(*LocationMessage)
+    if !ok {
+        return nil, unexpectedContent(content)
     }
-    location := c.Event.RawContent["location"].(map[string]interface{})
     return &MessageLocation{
-        Text: c.Event.RawContent["text"].(string),
-        Title: location["title"].(string),
-        Latitude: location["latitude"].(float64),
-        Longitude: location["latitude"].(float64),
+        Text: m.Text,
+        Title: m.Title,
+        Latitude: m.Latitude,
+        Longitude: m.Longitude,
     }, nil
 }
 func (c *EventContent) GetMessageSticker() (*MessageSticker, error) {
-    if c.ContentType != ContentTypeSticker {
-        return nil, errors.New("invalid contentType")
+    content, err := c.content(ContentTypeSticker)
+    if err != nil {
+        return nil, err
+    }
+    m, ok := content.(*StickerMessage)
+    if !ok {
+        return nil, unexpectedContent(content)
     }
-    metadata := c.Event.RawContent["contentMetadata"].(map[string]interface{})
     return &MessageSticker{
-        StickerId: metadata["STKID"].(string),
-        StickerPackageId: metadata["STKPKGID"].(string),
-        StickerVersion: metadata["STKVER"].(string),
+        StickerId: m.StickerId,
+        StickerPackageId: m.StickerPackageId,
+        StickerVersion: m.StickerVersion,
     }, nil
 }
 func (c *EventContent) GetMessageContact() (*MessageContact, error) {
-    if c.ContentType != ContentTypeContact {
-        return nil, errors.New("invalid contentType")
+    content, err := c.content(ContentTypeContact)
+    if err != nil {
+        return nil, err
+    }
+    m, ok := content.(*ContactMessage)
+    if !ok {
+        return nil, unexpectedContent(content)
     }
-    metadata := c.Event.RawContent["contentMetadata"].(map[string]interface{})
     return &MessageContact{
-        Mid: metadata["mid"].(string),
-        DisplayName: metadata["displayName"].(string),
+        Mid: m.Mid,
+        DisplayName: m.DisplayName,
     }, nil
 }
 
@@ -326,7 +427,6 @@ func NewMessageSticker(packageId, id, ver string) *MessageContent {
         },
     }
 }
-/*
 func NewMessageContact(mid, name string) *MessageContent {
     return &MessageContent{
         ContentType: ContentTypeContact,
@@ -336,11 +436,12 @@ func NewMessageContact(mid, name string) *MessageContent {
         },
     }
 }
-*/
 
 type MessageContentData struct {
     Reader io.ReadCloser
     ContentType string
+    // ContentLength is -1 when the server did not send Content-Length.
+    ContentLength int64
 }
 
 
@@ -370,9 +471,18 @@ type Client struct {
     BaseURL string
     HttpClient *http.Client
     Credential *Credential
-}
-func (c *Client) newRequest(method, url string, body io.Reader) (*http.Request, error) {
-    req, err := http.NewRequest(method, url, body)
+    // Retry is applied to every API call. A nil policy makes exactly one
+    // attempt.
+    Retry *RetryPolicy
+    // RateLimiter throttles outbound calls per endpoint. A nil limiter does
+    // not throttle.
+    RateLimiter *RateLimiter
+    // ValidateBeforeSend makes SendMessage and SendMessages validate their
+    // contents and return the *FieldError without calling the API.
+    ValidateBeforeSend bool
+}
+func (c *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+    req, err := http.NewRequestWithContext(ctx, method, url, body)
     if err != nil {
         return nil, err
     }
@@ -383,23 +493,73 @@ func (c *Client) newRequest(method, url string, body io.Reader) (*http.Request,
     return req, nil
 }
 
+// handleError returns the *APIError for a non-200 response. A body that
+// cannot be read completely is kept as far as it was read, so the status is
+// never lost.
 func (c *Client) handleError(resp *http.Response) error {
-    decoder := json.NewDecoder(resp.Body)
-    var e ErrorResponse;
-    err := decoder.Decode(&e)
-    if err != nil {
-        return err
+    body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64 * 1024))
+    apiErr := &APIError{
+        HTTPStatus: resp.StatusCode,
+        Body: body,
     }
-    return errors.New(fmt.Sprintf("%s: %s", e.StatusCode, e.StatusMessage))
-}
-
-func (c *Client) postEvents(to []string, event Event) error {
+    if resp.Request != nil {
+        apiErr.Path = resp.Request.URL.Path
+    }
+    apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+    var e ErrorResponse;
+    if json.Unmarshal(body, &e) == nil {
+        apiErr.StatusCode = e.StatusCode
+        apiErr.StatusMessage = e.StatusMessage
+    }
+    return apiErr
+}
+
+// do sends the request, retrying according to c.Retry, and returns the
+// response only when the server answered 200. Any other status is returned
+// as *APIError.
+func (c *Client) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+    for attempt := 1; ; attempt++ {
+        var reader io.Reader
+        if body != nil {
+            reader = bytes.NewReader(body)
+        }
+        req, err := c.newRequest(ctx, method, url, reader)
+        if err != nil {
+            return nil, err
+        }
+        err = c.RateLimiter.Wait(ctx, req.URL.Path)
+        if err != nil {
+            return nil, err
+        }
+        resp, err := c.HttpClient.Do(req)
+        if err == nil && resp.StatusCode != http.StatusOK {
+            err = c.handleError(resp)
+            resp.Body.Close()
+        }
+        c.RateLimiter.observe(req.URL.Path, err)
+        if err == nil {
+            c.Retry.report(attempt, req, nil, 0)
+            return resp, nil
+        }
+        wait, retry := c.Retry.backoff(attempt, err)
+        if !retry || ctx.Err() != nil {
+            c.Retry.report(attempt, req, err, 0)
+            return nil, err
+        }
+        c.Retry.report(attempt, req, err, wait)
+        if err := sleepContext(ctx, wait); err != nil {
+            return nil, err
+        }
+    }
+}
+
+func (c *Client) postEvents(ctx context.Context, to []string, event Event) error {
     // Build endpoint URL
     url, err := url.Parse(c.BaseURL)
     if err != nil {
         return err
     }
-    url.Path = "/v1/events"
+    url.Path = EndpointEvents
 
     // JSON encoding
     b, err := json.Marshal(event)
@@ -408,23 +568,25 @@ func (c *Client) postEvents(to []string, event Event) error {
     }
 
     // POST event
-    req, err := c.newRequest("POST", url.String(), bytes.NewBuffer(b))
-    if err != nil {
-        return err
-    }
-    resp, err := c.HttpClient.Do(req)
+    resp, err := c.do(ctx, "POST", url.String(), b)
     if err != nil {
         return err
     }
-    defer resp.Body.Close()
-    if resp.StatusCode != http.StatusOK {
-        return c.handleError(resp)
-    }
+    resp.Body.Close()
     return nil
 }
 
 func (c *Client) SendMessage(to []string, content *MessageContent) error {
-    return c.postEvents(to, Event{
+    return c.SendMessageContext(context.Background(), to, content)
+}
+
+func (c *Client) SendMessageContext(ctx context.Context, to []string, content *MessageContent) error {
+    if c.ValidateBeforeSend {
+        if err := content.Validate(); err != nil {
+            return err
+        }
+    }
+    return c.postEvents(ctx, to, Event{
         To: to,
         ToChannel: 1383378250,
         EventType: "138311608800106203",
@@ -433,35 +595,68 @@ func (c *Client) SendMessage(to []string, content *MessageContent) error {
 }
 
 func (c *Client) SendText(to []string, text string) error {
-    return c.SendMessage(to, NewMessageText(text))
+    return c.SendTextContext(context.Background(), to, text)
+}
+
+func (c *Client) SendTextContext(ctx context.Context, to []string, text string) error {
+    return c.SendMessageContext(ctx, to, NewMessageText(text))
 }
 
 func (c *Client) SendImage(to []string, contentURL, previewURL string) error {
-    return c.SendMessage(to, NewMessageImage(contentURL, previewURL))
+    return c.SendImageContext(context.Background(), to, contentURL, previewURL)
+}
+
+func (c *Client) SendImageContext(ctx context.Context, to []string, contentURL, previewURL string) error {
+    return c.SendMessageContext(ctx, to, NewMessageImage(contentURL, previewURL))
 }
 
 func (c *Client) SendVideo(to []string, contentURL, previewURL string) error {
-    return c.SendMessage(to, NewMessageVideo(contentURL, previewURL))
+    return c.SendVideoContext(context.Background(), to, contentURL, previewURL)
+}
+
+func (c *Client) SendVideoContext(ctx context.Context, to []string, contentURL, previewURL string) error {
+    return c.SendMessageContext(ctx, to, NewMessageVideo(contentURL, previewURL))
 }
 
 func (c *Client) SendAudio(to []string, contentURL string, length int) error {
-    return c.SendMessage(to, NewMessageAudio(contentURL, length))
+    return c.SendAudioContext(context.Background(), to, contentURL, length)
+}
+
+func (c *Client) SendAudioContext(ctx context.Context, to []string, contentURL string, length int) error {
+    return c.SendMessageContext(ctx, to, NewMessageAudio(contentURL, length))
 }
 
 func (c *Client) SendLocation(to []string, text, title string, lat, long float64) error {
-    return c.SendMessage(to, NewMessageLocation(text, title, lat, long))
+    return c.SendLocationContext(context.Background(), to, text, title, lat, long)
+}
+
+func (c *Client) SendLocationContext(ctx context.Context, to []string, text, title string, lat, long float64) error {
+    return c.SendMessageContext(ctx, to, NewMessageLocation(text, title, lat, long))
 }
 
 func (c *Client) SendSticker(to []string, packageId, id, ver string) error {
-    return c.SendMessage(to, NewMessageSticker(packageId, id, ver))
+    return c.SendStickerContext(context.Background(), to, packageId, id, ver)
+}
+
+func (c *Client) SendStickerContext(ctx context.Context, to []string, packageId, id, ver string) error {
+    return c.SendMessageContext(ctx, to, NewMessageSticker(packageId, id, ver))
 }
 
 func (c *Client) SendMessages(to []string, contents []*MessageContent, notified int) error {
+    return c.SendMessagesContext(context.Background(), to, contents, notified)
+}
+
+func (c *Client) SendMessagesContext(ctx context.Context, to []string, contents []*MessageContent, notified int) error {
+    if c.ValidateBeforeSend {
+        if err := ValidateMessages(contents, notified); err != nil {
+            return err
+        }
+    }
     messages := make([]map[string]interface{}, len(contents))
     for i, c := range contents {
         messages[i] = c.Content.Map()
     }
-    return c.postEvents(to, Event{
+    return c.postEvents(ctx, to, Event{
         To: to,
         ToChannel: 1383378250,
         EventType: "140177271400161403",
@@ -473,49 +668,99 @@ func (c *Client) SendMessages(to []string, contents []*MessageContent, notified
 }
 
 func (c *Client) GetMessageContent(m *EventContent) (*MessageContentData, error) {
+    return c.GetMessageContentContext(context.Background(), m)
+}
+
+// GetMessageContentContext returns the content of an image, video or audio
+// message. The caller must close the returned Reader.
+func (c *Client) GetMessageContentContext(ctx context.Context, m *EventContent) (*MessageContentData, error) {
+    return c.getContent(ctx, fmt.Sprintf("/v1/bot/message/%s/content", m.Id))
+}
+
+func (c *Client) GetMessagePreviewContent(m *EventContent) (*MessageContentData, error) {
+    return c.GetMessagePreviewContentContext(context.Background(), m)
+}
+
+// GetMessagePreviewContentContext returns the preview image of an image or
+// video message. The caller must close the returned Reader.
+func (c *Client) GetMessagePreviewContentContext(ctx context.Context, m *EventContent) (*MessageContentData, error) {
+    return c.getContent(ctx, fmt.Sprintf("/v1/bot/message/%s/content/preview", m.Id))
+}
+
+func (c *Client) getContent(ctx context.Context, path string) (*MessageContentData, error) {
     // Build endpoint URL
     url, err := url.Parse(c.BaseURL)
     if err != nil {
         return nil, err
     }
-    url.Path = fmt.Sprintf("/v1/bot/message/%s/content", m.Id)
-    req, err := c.newRequest("GET", url.String(), nil)
+    url.Path = path
+    resp, err := c.do(ctx, "GET", url.String(), nil)
     if err != nil {
         return nil, err
     }
-    resp, err := c.HttpClient.Do(req)
-    if err != nil {
-        return nil, err
-    }
-    if resp.StatusCode != http.StatusOK {
-        return nil, c.handleError(resp)
-    }
     return &MessageContentData{
         Reader: resp.Body,
         ContentType: resp.Header.Get("Content-Type"),
+        ContentLength: resp.ContentLength,
     }, nil
 }
 
 func (c *Client) GetUserProfiles(mids []string) (*Contacts, error) {
+    return c.GetUserProfilesContext(context.Background(), mids)
+}
+
+// GetUserProfilesContext splits mids into requests of at most
+// MaxProfileMids mids, follows the Start/Display paging of each response and
+// merges the results.
+func (c *Client) GetUserProfilesContext(ctx context.Context, mids []string) (*Contacts, error) {
+    merged := &Contacts{
+        Contacts: []Contact{},
+    }
+    for i, chunk := range splitMids(mids) {
+        start := 0
+        for {
+            contacts, err := c.getUserProfilesPage(ctx, chunk, start)
+            if err != nil {
+                return nil, err
+            }
+            if ignoredStart(contacts, start) {
+                break
+            }
+            if start == 0 {
+                if i == 0 {
+                    merged.Start = contacts.Start
+                }
+                merged.Total += contacts.Total
+            }
+            merged.Contacts = append(merged.Contacts, contacts.Contacts...)
+            merged.Count += contacts.Count
+            merged.Display += contacts.Display
+            next, more := nextPageStart(contacts)
+            if !more {
+                break
+            }
+            start = next
+        }
+    }
+    return merged, nil
+}
+
+func (c *Client) getUserProfilesPage(ctx context.Context, mids []string, start int) (*Contacts, error) {
     // Build endpoint URL
     url, err := url.Parse(c.BaseURL)
     if err != nil {
         return nil, err
     }
-    url.Path = "/v1/profiles"
+    url.Path = EndpointProfiles
     url.RawQuery = fmt.Sprintf("mids=%s", strings.Join(mids, ","))
-    req, err := c.newRequest("GET", url.String(), nil)
-    if err != nil {
-        return nil, err
+    if start > 0 {
+        url.RawQuery += fmt.Sprintf("&start=%d", start)
     }
-    resp, err := c.HttpClient.Do(req)
+    resp, err := c.do(ctx, "GET", url.String(), nil)
     if err != nil {
         return nil, err
     }
     defer resp.Body.Close()
-    if resp.StatusCode != http.StatusOK {
-        return nil, c.handleError(resp)
-    }
     decoder := json.NewDecoder(resp.Body)
     var contacts Contacts;
     err = decoder.Decode(&contacts)
@@ -533,8 +778,17 @@ func NewClient(cred *Credential) *Client {
     }
 }
 
+var (
+    ErrSignatureNotFound = errors.New("Not found HTTP header: 'X-LINE-ChannelSignature'.")
+    ErrInvalidSignature = errors.New("Invalid signature.")
+)
+
 type callbackRequest struct {
-    Result []Event
+    Result []json.RawMessage
+}
+
+type callbackEventContent struct {
+    Content json.RawMessage `json:"content"`
 }
 
 func ParseRequest(r *http.Request, cred *Credential) ([]Event, error) {
@@ -542,24 +796,12 @@ func ParseRequest(r *http.Request, cred *Credential) ([]Event, error) {
     buf := new(bytes.Buffer)
     buf.ReadFrom(r.Body)
 
-    // Get request signature
-    sign := r.Header.Get("X-LINE-ChannelSignature")
-    if sign == "" {
-        return nil, errors.New("Not found HTTP header: 'X-LINE-ChannelSignature'.")
-    }
-    expectedMAC, err := base64.StdEncoding.DecodeString(sign)
+    // Validate body
+    err := cred.VerifySignature(buf.Bytes(), r.Header.Get("X-LINE-ChannelSignature"))
     if err != nil {
         return nil, err
     }
 
-    // Validate body
-    mac := hmac.New(sha256.New, []byte(cred.ChannelSecret))
-    mac.Write(buf.Bytes())
-    messageMAC := mac.Sum(nil)
-    if !hmac.Equal(messageMAC, expectedMAC) {
-        return nil, errors.New("Invalid signature.")
-    }
-
     // Decode json
     decoder := json.NewDecoder(buf)
     var result callbackRequest;
@@ -567,5 +809,24 @@ func ParseRequest(r *http.Request, cred *Credential) ([]Event, error) {
     if err != nil {
         return nil, err
     }
-    return result.Result, nil
+    // An event that cannot be decoded keeps its error in Err, so one bad
+    // event does not discard the rest of the callback.
+    events := make([]Event, len(result.Result))
+    for i, raw := range result.Result {
+        events[i].Content, events[i].Err = decodeEvent(raw, &events[i])
+    }
+    return events, nil
+}
+
+func decodeEvent(raw json.RawMessage, event *Event) (Content, error) {
+    err := json.Unmarshal(raw, event)
+    if err != nil {
+        return nil, err
+    }
+    var content callbackEventContent
+    err = json.Unmarshal(raw, &content)
+    if err != nil {
+        return nil, err
+    }
+    return decodeContent(content.Content)
 }
@@ -4,7 +4,10 @@ import (
     "testing"
 
     "fmt"
+    "time"
     "bytes"
+    "errors"
+    "context"
     "io/ioutil"
     "net/http"
     "net/http/httptest"
@@ -29,7 +32,11 @@ func Test_ParseRequest_Success(t *testing.T) {
             return
         }
         for _, event := range result {
-            content := event.GetEventContent()
+            content, err := event.GetEventContent()
+            if err != nil {
+                t.Error(err)
+                return
+            }
             if content.IsMessage {
                 if content.ContentType == ContentTypeText {
                     msg, err := content.GetMessageText()
@@ -68,6 +75,187 @@ func Test_ParseRequest_Success(t *testing.T) {
     }
 }
 
+func Test_GetEventContent_MissingField(t *testing.T) {
+    event := Event{
+        RawContent: map[string]interface{}{
+            "id": "1",
+            "from": "abced",
+            "to": []interface{}{"abced"},
+            "toType": float64(1),
+            "contentType": float64(1),
+        },
+    }
+    _, err := event.GetEventContent()
+    fieldErr, ok := err.(*FieldError)
+    if !ok {
+        t.Errorf("excepted: *FieldError, actual: %#v", err)
+        return
+    }
+    if fieldErr.Field != "content.createdTime" {
+        t.Errorf("excepted: 'content.createdTime', actual: '%s'", fieldErr.Field)
+    }
+}
+
+func Test_GetEventContent_InvalidField(t *testing.T) {
+    var event Event
+    err := json.Unmarshal([]byte(`{"content":{"toType":1,"createdTime":"1460529367936","from":"abced","id":"1","to":["abced"],"contentType":1}}`), &event)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    _, err = event.GetEventContent()
+    fieldErr, ok := err.(*FieldError)
+    if !ok {
+        t.Errorf("excepted: *FieldError, actual: %#v", err)
+        return
+    }
+    if fieldErr.Field != "content.createdTime" || fieldErr.Expected != "number" || fieldErr.Actual != "string" {
+        t.Errorf("unexpected error: %s", fieldErr)
+    }
+}
+
+func Test_GetEventContent_InvalidMetadata(t *testing.T) {
+    var event Event
+    err := json.Unmarshal([]byte(`{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"1","to":["abced"],"contentType":8,"contentMetadata":{"STKID":"3","STKPKGID":332}}}`), &event)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    _, err = event.GetEventContent()
+    fieldErr, ok := err.(*FieldError)
+    if !ok {
+        t.Errorf("excepted: *FieldError, actual: %#v", err)
+        return
+    }
+    if fieldErr.Field != "content.contentMetadata.STKPKGID" || fieldErr.Expected != "string" || fieldErr.Actual != "number" {
+        t.Errorf("unexpected error: %s", fieldErr)
+    }
+}
+
+func Test_ParseRequest_TypedContent(t *testing.T) {
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0000000000000000",
+        Mid: "0123456789abcdef0000000000000000",
+    }
+    body := []byte(`{"result":[` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"1","to":["abced"],"contentType":8,"contentMetadata":{"STKID":"3","STKPKGID":"332","STKVER":"100"}}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"2","to":["abced"],"contentType":7,"text":"here","location":{"title":"Tokyo","latitude":35.6,"longitude":139.7}}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"3","to":["abced"],"opType":4}}]}`)
+    mac := hmac.New(sha256.New, []byte(cred.ChannelSecret))
+    mac.Write(body)
+    req, err := http.NewRequest("POST", "/callback", bytes.NewBuffer(body))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    req.Header.Set("X-LINE-ChannelSignature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+    events, err := ParseRequest(req, cred)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(events) != 3 {
+        t.Errorf("excepted: 3, actual: %d", len(events))
+        return
+    }
+    sticker, ok := events[0].Content.(*StickerMessage)
+    if !ok || sticker.StickerPackageId != "332" || sticker.Id != "1" {
+        t.Errorf("unexpected content: %#v", events[0].Content)
+    }
+    location, ok := events[1].Content.(*LocationMessage)
+    if !ok || location.Latitude != 35.6 || location.Longitude != 139.7 {
+        t.Errorf("unexpected content: %#v", events[1].Content)
+    }
+    if _, ok := events[2].Content.(*AddedOperation); !ok {
+        t.Errorf("unexpected content: %#v", events[2].Content)
+    }
+    content, err := events[2].GetEventContent()
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if !content.IsOperation || content.OpType != OpTypeAdded {
+        t.Errorf("unexpected event content: %#v", content)
+    }
+}
+
+func Test_ParseRequest_MixedBatch(t *testing.T) {
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0000000000000000",
+        Mid: "0123456789abcdef0000000000000000",
+    }
+    body := []byte(`{"result":[` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"1","to":["abced"],"contentType":1,"text":"good","contentMetadata":{"EMTVER":4}}},` +
+        `{"content":{"toType":1,"from":"abced","id":"2","to":["abced"],"contentType":1,"text":"no createdTime"}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"3","to":["abced"],"contentType":8,"contentMetadata":{"STKID":3,"STKPKGID":"332","STKVER":"100"}}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"4","to":["abced"],"opType":8}}]}`)
+    mac := hmac.New(sha256.New, []byte(cred.ChannelSecret))
+    mac.Write(body)
+    req, _ := http.NewRequest("POST", "/callback", bytes.NewBuffer(body))
+    req.Header.Set("X-LINE-ChannelSignature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+    events, err := ParseRequest(req, cred)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(events) != 4 {
+        t.Errorf("excepted: 4, actual: %d", len(events))
+        return
+    }
+    if text, ok := events[0].Content.(*TextMessage); !ok || text.Text != "good" || events[0].Err != nil {
+        t.Errorf("unexpected event: %#v", events[0])
+    }
+    if e, ok := events[1].Err.(*FieldError); !ok || e.Field != "content.createdTime" || events[1].Content != nil {
+        t.Errorf("unexpected event: %#v", events[1])
+    }
+    if e, ok := events[2].Err.(*FieldError); !ok || e.Field != "content.contentMetadata.STKID" {
+        t.Errorf("unexpected event: %#v", events[2])
+    }
+    if _, err := events[2].GetEventContent(); err != events[2].Err {
+        t.Errorf("excepted: %v, actual: %v", events[2].Err, err)
+    }
+    if _, ok := events[3].Content.(*BlockedOperation); !ok {
+        t.Errorf("unexpected event: %#v", events[3])
+    }
+}
+
+func Test_GetEventContent_NoMutation(t *testing.T) {
+    event := Event{
+        RawContent: map[string]interface{}{
+            "id": "1",
+            "from": "abced",
+            "createdTime": float64(1460529367936),
+            "to": []interface{}{"abced"},
+            "toType": float64(1),
+            "contentType": float64(ContentTypeText),
+            "text": "hello",
+        },
+    }
+    content, err := event.GetEventContent()
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if event.Content != nil {
+        t.Error("event was modified")
+    }
+    msg, err := content.GetMessageText()
+    if err != nil || msg.Text != "hello" {
+        t.Errorf("unexpected message: %v %v", msg, err)
+    }
+
+    // a mismatched ContentType returns an error instead of panicking
+    content.ContentType = ContentTypeAudio
+    _, err = content.GetMessageAudio()
+    if err == nil {
+        t.Error("err is nil")
+    }
+}
+
 func Test_SendMessage_Success(t *testing.T) {
     server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
         w.WriteHeader(200)
@@ -185,3 +373,56 @@ func Test_GetMessageContent_Success(t *testing.T) {
         t.Errorf("excepted: 'application/json', actual: '%s'", data.ContentType)
     }
 }
+
+func Test_SendMessageContext_Canceled(t *testing.T) {
+    received := make(chan struct{})
+    release := make(chan struct{})
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        close(received)
+        <-release
+    }))
+    defer server.Close()
+    defer close(release)
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+
+    ctx, cancel := context.WithCancel(context.Background())
+    go func() {
+        <-received
+        cancel()
+    }()
+    err := client.SendMessageContext(ctx, []string{"test"}, NewMessageText("message"))
+    if !errors.Is(err, context.Canceled) {
+        t.Errorf("excepted: context.Canceled, actual: %v", err)
+    }
+}
+
+func Test_GetUserProfilesContext_Deadline(t *testing.T) {
+    release := make(chan struct{})
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        <-release
+    }))
+    defer server.Close()
+    defer close(release)
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+
+    ctx, cancel := context.WithTimeout(context.Background(), 50 * time.Millisecond)
+    defer cancel()
+    _, err := client.GetUserProfilesContext(ctx, []string{"u0047556f2e40dba2456887320ba7c76d"})
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("excepted: context.DeadlineExceeded, actual: %v", err)
+    }
+}
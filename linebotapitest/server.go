@@ -0,0 +1,339 @@
+// Package linebotapitest provides an in-process fake of the LINE Bot API
+// server for tests of code built on linebotapi.
+//
+//     server := linebotapitest.NewServer(nil)
+//     defer server.Close()
+//     client := server.APIClient()
+//     client.SendText([]string{"u0123"}, "Hello")
+//     events := server.Events()
+package linebotapitest
+
+import (
+    "fmt"
+    "sync"
+    "bytes"
+    "time"
+    "strconv"
+    "strings"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+)
+
+// Failure is a scripted response returned instead of the normal one.
+type Failure struct {
+    Status int
+    // Body defaults to a JSON error body for Status.
+    Body string
+    Header http.Header
+    // CloseConnection drops the connection without writing a response.
+    CloseConnection bool
+}
+
+type content struct {
+    contentType string
+    data []byte
+}
+
+// Server fakes /v1/events, /v1/profiles and the message content and preview
+// endpoints. Every request must carry the channel headers of Credential.
+type Server struct {
+    *httptest.Server
+    Credential *linebotapi.Credential
+
+    mu sync.Mutex
+    events []linebotapi.Event
+    requests map[string]int
+    profiles map[string]linebotapi.Contact
+    contents map[string]content
+    previews map[string]content
+    failures map[string][]Failure
+    latency time.Duration
+    pageSize int
+}
+
+// NewServer starts a fake server. A nil cred uses a fixed test credential.
+func NewServer(cred *linebotapi.Credential) *Server {
+    if cred == nil {
+        cred = &linebotapi.Credential{
+            ChannelId: 1234567890,
+            ChannelSecret: "0123456789abcdef0123456789abcdef",
+            Mid: "u0123456789abcdef0123456789abcdef",
+        }
+    }
+    s := &Server{
+        Credential: cred,
+        requests: make(map[string]int),
+        profiles: make(map[string]linebotapi.Contact),
+        contents: make(map[string]content),
+        previews: make(map[string]content),
+        failures: make(map[string][]Failure),
+    }
+    s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+    return s
+}
+
+// APIClient returns a linebotapi.Client pointed at the server. Client is
+// left to httptest.Server and returns the underlying http.Client.
+func (s *Server) APIClient() *linebotapi.Client {
+    client := linebotapi.NewClient(s.Credential)
+    client.BaseURL = s.URL
+    client.HttpClient = s.Server.Client()
+    return client
+}
+
+// Events returns every event received on /v1/events, in order.
+func (s *Server) Events() []linebotapi.Event {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    events := make([]linebotapi.Event, len(s.events))
+    copy(events, s.events)
+    return events
+}
+
+// Requests returns the number of requests received for the endpoint, e.g.
+// linebotapi.EndpointEvents. Message content requests are counted under
+// "/v1/bot/message/{id}/content" and preview requests under
+// "/v1/bot/message/{id}/content/preview".
+func (s *Server) Requests(endpoint string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.requests[endpoint]
+}
+
+// Reset forgets received events, request counts and pending failures.
+func (s *Server) Reset() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.events = nil
+    s.requests = make(map[string]int)
+    s.failures = make(map[string][]Failure)
+}
+
+func (s *Server) AddProfile(contact linebotapi.Contact) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.profiles[contact.Mid] = contact
+}
+
+// SetProfilePageSize limits the contacts returned per /v1/profiles response.
+// Zero returns all of them.
+func (s *Server) SetProfilePageSize(n int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.pageSize = n
+}
+
+// SetContent registers the data served for a message id.
+func (s *Server) SetContent(messageId, contentType string, data []byte) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.contents[messageId] = content{contentType: contentType, data: data}
+}
+
+// SetPreviewContent registers the preview image served for a message id.
+func (s *Server) SetPreviewContent(messageId, contentType string, data []byte) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.previews[messageId] = content{contentType: contentType, data: data}
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.latency = d
+}
+
+// Fail makes the next n requests for the endpoint return f.
+func (s *Server) Fail(endpoint string, n int, f Failure) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for i := 0; i < n; i++ {
+        s.failures[endpoint] = append(s.failures[endpoint], f)
+    }
+}
+
+func endpointOf(path string) string {
+    if strings.HasPrefix(path, "/v1/bot/message/") {
+        if strings.HasSuffix(path, "/preview") {
+            return "/v1/bot/message/{id}/content/preview"
+        }
+        return "/v1/bot/message/{id}/content"
+    }
+    return path
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+    w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(linebotapi.ErrorResponse{
+        StatusCode: strconv.Itoa(status),
+        StatusMessage: message,
+    })
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+    // Consuming the body lets the request context observe client
+    // disconnects while the response is delayed.
+    body, err := ioutil.ReadAll(r.Body)
+    if err != nil {
+        return
+    }
+    r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+    endpoint := endpointOf(r.URL.Path)
+    s.mu.Lock()
+    s.requests[endpoint]++
+    latency := s.latency
+    var failure *Failure
+    if pending := s.failures[endpoint]; len(pending) > 0 {
+        failure = &pending[0]
+        s.failures[endpoint] = pending[1:]
+    }
+    s.mu.Unlock()
+
+    if latency > 0 {
+        select {
+        case <-time.After(latency):
+        case <-r.Context().Done():
+            return
+        }
+    }
+    if failure != nil {
+        s.writeFailure(w, failure)
+        return
+    }
+    if !s.authorized(r) {
+        writeError(w, http.StatusUnauthorized, "Authentication failed")
+        return
+    }
+
+    switch {
+    case r.URL.Path == linebotapi.EndpointEvents && r.Method == "POST":
+        s.serveEvents(w, r)
+    case r.URL.Path == linebotapi.EndpointProfiles && r.Method == "GET":
+        s.serveProfiles(w, r)
+    case strings.HasPrefix(endpoint, "/v1/bot/message/") && r.Method == "GET":
+        s.serveContent(w, r)
+    default:
+        writeError(w, http.StatusNotFound, "Not found")
+    }
+}
+
+func (s *Server) writeFailure(w http.ResponseWriter, f *Failure) {
+    if f.CloseConnection {
+        if hijacker, ok := w.(http.Hijacker); ok {
+            conn, _, err := hijacker.Hijack()
+            if err == nil {
+                conn.Close()
+                return
+            }
+        }
+        panic(http.ErrAbortHandler)
+    }
+    for key, values := range f.Header {
+        for _, value := range values {
+            w.Header().Add(key, value)
+        }
+    }
+    if f.Body == "" {
+        writeError(w, f.Status, http.StatusText(f.Status))
+        return
+    }
+    w.WriteHeader(f.Status)
+    fmt.Fprint(w, f.Body)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+    return r.Header.Get("X-Line-ChannelID") == strconv.Itoa(s.Credential.ChannelId) &&
+        r.Header.Get("X-Line-ChannelSecret") == s.Credential.ChannelSecret &&
+        r.Header.Get("X-Line-Trusted-User-With-ACL") == s.Credential.Mid
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+    var event linebotapi.Event
+    if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+        writeError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    if len(event.To) == 0 {
+        writeError(w, http.StatusBadRequest, "to is empty")
+        return
+    }
+    if len(event.To) > linebotapi.MaxRecipients {
+        writeError(w, http.StatusBadRequest, "too many recipients")
+        return
+    }
+    s.mu.Lock()
+    s.events = append(s.events, event)
+    n := len(s.events)
+    s.mu.Unlock()
+
+    w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+    fmt.Fprintf(w, `{"failed":[],"messageId":"%d","timestamp":%d,"version":1}`, n, time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+func (s *Server) serveProfiles(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    if query.Get("mids") == "" {
+        writeError(w, http.StatusBadRequest, "mids is empty")
+        return
+    }
+    start := 1
+    if value := query.Get("start"); value != "" {
+        n, err := strconv.Atoi(value)
+        if err != nil || n < 1 {
+            writeError(w, http.StatusBadRequest, "invalid start")
+            return
+        }
+        start = n
+    }
+
+    s.mu.Lock()
+    var all []linebotapi.Contact
+    for _, mid := range strings.Split(query.Get("mids"), ",") {
+        if contact, exists := s.profiles[mid]; exists {
+            all = append(all, contact)
+        }
+    }
+    pageSize := s.pageSize
+    s.mu.Unlock()
+
+    contacts := linebotapi.Contacts{
+        Contacts: []linebotapi.Contact{},
+        Start: start,
+        Total: len(all),
+    }
+    for i := start - 1; i < len(all) && (pageSize == 0 || len(contacts.Contacts) < pageSize); i++ {
+        contacts.Contacts = append(contacts.Contacts, all[i])
+    }
+    contacts.Count = len(contacts.Contacts)
+    contacts.Display = len(contacts.Contacts)
+    w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+    json.NewEncoder(w).Encode(contacts)
+}
+
+func (s *Server) serveContent(w http.ResponseWriter, r *http.Request) {
+    parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/bot/message/"), "/")
+    contents := s.contents
+    if len(parts) == 3 && parts[1] == "content" && parts[2] == "preview" {
+        contents = s.previews
+    } else if len(parts) != 2 || parts[1] != "content" {
+        writeError(w, http.StatusNotFound, "Not found")
+        return
+    }
+    s.mu.Lock()
+    c, exists := contents[parts[0]]
+    s.mu.Unlock()
+    if !exists {
+        writeError(w, http.StatusNotFound, "Not found")
+        return
+    }
+    w.Header().Set("Content-Type", c.contentType)
+    w.Header().Set("Content-Length", strconv.Itoa(len(c.data)))
+    w.Write(c.data)
+}
@@ -0,0 +1,129 @@
+package linebotapitest
+
+import (
+    "testing"
+
+    "time"
+    "errors"
+    "context"
+    "io/ioutil"
+
+    "github.com/mokejp/linebotapi"
+)
+
+func Test_Server_RecordEvents(t *testing.T) {
+    server := NewServer(nil)
+    defer server.Close()
+
+    client := server.APIClient()
+    err := client.SendText([]string{"u1", "u2"}, "Hello")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    err = client.SendSticker([]string{"u1"}, "1", "2", "100")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    events := server.Events()
+    if len(events) != 2 {
+        t.Errorf("excepted: 2, actual: %d", len(events))
+        return
+    }
+    if len(events[0].To) != 2 || events[0].RawContent["text"] != "Hello" {
+        t.Errorf("unexpected event: %#v", events[0])
+    }
+    if server.Requests(linebotapi.EndpointEvents) != 2 {
+        t.Errorf("excepted: 2, actual: %d", server.Requests(linebotapi.EndpointEvents))
+    }
+}
+
+func Test_Server_Credential(t *testing.T) {
+    server := NewServer(nil)
+    defer server.Close()
+
+    client := linebotapi.NewClient(&linebotapi.Credential{
+        ChannelId: server.Credential.ChannelId,
+        ChannelSecret: "wrong",
+        Mid: server.Credential.Mid,
+    })
+    client.BaseURL = server.URL
+    err := client.SendText([]string{"u1"}, "Hello")
+    if !linebotapi.IsAuthError(err) {
+        t.Errorf("excepted: auth error, actual: %v", err)
+    }
+    if len(server.Events()) != 0 {
+        t.Errorf("excepted: 0, actual: %d", len(server.Events()))
+    }
+}
+
+func Test_Server_ProfilesAndContent(t *testing.T) {
+    server := NewServer(nil)
+    defer server.Close()
+    server.AddProfile(linebotapi.Contact{Mid: "u1", DisplayName: "one"})
+    server.AddProfile(linebotapi.Contact{Mid: "u2", DisplayName: "two"})
+    server.SetContent("100", "image/jpeg", []byte("jpeg"))
+    server.SetProfilePageSize(1)
+
+    client := server.APIClient()
+    var names []string
+    it := client.IterateUserProfiles(context.Background(), []string{"u1", "u2", "u3"})
+    for it.Next() {
+        names = append(names, it.Contact().DisplayName)
+    }
+    if it.Err() != nil || len(names) != 2 || names[1] != "two" {
+        t.Errorf("unexpected profiles: %v %v", names, it.Err())
+    }
+
+    data, err := client.GetMessageContent(&linebotapi.EventContent{Id: "100"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    defer data.Reader.Close()
+    buf, _ := ioutil.ReadAll(data.Reader)
+    if string(buf) != "jpeg" || data.ContentType != "image/jpeg" {
+        t.Errorf("unexpected content: %s %s", buf, data.ContentType)
+    }
+    _, err = client.GetMessageContent(&linebotapi.EventContent{Id: "404"})
+    var apiErr *linebotapi.APIError
+    if !errors.As(err, &apiErr) || apiErr.HTTPStatus != 404 {
+        t.Errorf("excepted: 404, actual: %v", err)
+    }
+}
+
+func Test_Server_ScriptedFailures(t *testing.T) {
+    server := NewServer(nil)
+    defer server.Close()
+    server.Fail(linebotapi.EndpointEvents, 1, Failure{CloseConnection: true})
+    server.Fail(linebotapi.EndpointEvents, 1, Failure{Status: 503})
+
+    client := server.APIClient()
+    client.Retry = &linebotapi.RetryPolicy{
+        MaxAttempts: 3,
+        InitialBackoff: time.Millisecond,
+    }
+    err := client.SendText([]string{"u1"}, "Hello")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if server.Requests(linebotapi.EndpointEvents) != 3 || len(server.Events()) != 1 {
+        t.Errorf("unexpected requests: %d", server.Requests(linebotapi.EndpointEvents))
+    }
+}
+
+func Test_Server_Latency(t *testing.T) {
+    server := NewServer(nil)
+    defer server.Close()
+    server.SetLatency(time.Second)
+
+    client := server.APIClient()
+    ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+    defer cancel()
+    err := client.SendTextContext(ctx, []string{"u1"}, "Hello")
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("excepted: context.DeadlineExceeded, actual: %v", err)
+    }
+}
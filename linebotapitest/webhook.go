@@ -0,0 +1,159 @@
+package linebotapitest
+
+import (
+    "fmt"
+    "time"
+    "bytes"
+    "strconv"
+    "net/http"
+    "encoding/json"
+
+    "github.com/mokejp/linebotapi"
+)
+
+const (
+    EventTypeReceivingMessage = "138311609000106303"
+    EventTypeReceivingOperation = "138311609100106403"
+)
+
+// Tamper selects how NewTamperedWebhookRequest breaks the signature header.
+type Tamper int
+
+const (
+    TamperNone Tamper = iota
+    // TamperBadSignature signs the body with the wrong channel secret.
+    TamperBadSignature
+    // TamperMissingSignature omits the X-LINE-ChannelSignature header.
+    TamperMissingSignature
+    // TamperMalformedSignature sends a value that is not base64.
+    TamperMalformedSignature
+)
+
+// NewWebhookRequest builds a callback request to target carrying contents,
+// signed with cred as the Bot API server would sign it. Empty header fields
+// are filled in: Id with a sequence number, From with a fixed user mid,
+// CreatedTime with the current time, To with cred.Mid and ToType with
+// linebotapi.ToTypeUser.
+func NewWebhookRequest(cred *linebotapi.Credential, target string, contents ...linebotapi.Content) (*http.Request, error) {
+    return NewTamperedWebhookRequest(cred, target, TamperNone, contents...)
+}
+
+func NewTamperedWebhookRequest(cred *linebotapi.Credential, target string, tamper Tamper, contents ...linebotapi.Content) (*http.Request, error) {
+    body, err := CallbackBody(cred, contents...)
+    if err != nil {
+        return nil, err
+    }
+    req, err := http.NewRequest("POST", target, bytes.NewReader(body))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+    switch tamper {
+    case TamperNone:
+        req.Header.Set("X-LINE-ChannelSignature", cred.Sign(body))
+    case TamperBadSignature:
+        wrong := *cred
+        wrong.ChannelSecret += "x"
+        req.Header.Set("X-LINE-ChannelSignature", wrong.Sign(body))
+    case TamperMalformedSignature:
+        req.Header.Set("X-LINE-ChannelSignature", "%not base64%")
+    }
+    return req, nil
+}
+
+// CallbackBody returns the JSON body of a callback carrying contents.
+func CallbackBody(cred *linebotapi.Credential, contents ...linebotapi.Content) ([]byte, error) {
+    result := make([]map[string]interface{}, len(contents))
+    for i, content := range contents {
+        event, err := callbackEvent(cred, i, content)
+        if err != nil {
+            return nil, err
+        }
+        result[i] = event
+    }
+    return json.Marshal(map[string]interface{}{
+        "result": result,
+    })
+}
+
+func callbackEvent(cred *linebotapi.Credential, index int, content linebotapi.Content) (map[string]interface{}, error) {
+    header := *content.Header()
+    if header.Id == "" {
+        header.Id = strconv.Itoa(index + 1)
+    }
+    if header.From == "" {
+        header.From = "u206d25c2ea6bd87c17655609a1c37cb8"
+    }
+    if header.CreatedTime == 0 {
+        header.CreatedTime = time.Now().UnixNano() / int64(time.Millisecond)
+    }
+    if len(header.To) == 0 {
+        header.To = []string{cred.Mid}
+    }
+    if header.ToType == 0 {
+        header.ToType = linebotapi.ToTypeUser
+    }
+    raw := map[string]interface{}{
+        "id": header.Id,
+        "from": header.From,
+        "createdTime": header.CreatedTime,
+        "to": header.To,
+        "toType": header.ToType,
+    }
+
+    eventType := EventTypeReceivingMessage
+    switch m := content.(type) {
+    case *linebotapi.TextMessage:
+        raw["contentType"] = linebotapi.ContentTypeText
+        raw["text"] = m.Text
+    case *linebotapi.ImageMessage:
+        raw["contentType"] = linebotapi.ContentTypeImage
+    case *linebotapi.VideoMessage:
+        raw["contentType"] = linebotapi.ContentTypeVideo
+    case *linebotapi.AudioMessage:
+        raw["contentType"] = linebotapi.ContentTypeAudio
+        raw["contentMetadata"] = map[string]string{
+            "AUDLEN": strconv.Itoa(m.AudioLength),
+        }
+    case *linebotapi.LocationMessage:
+        raw["contentType"] = linebotapi.ContentTypeLocation
+        raw["text"] = m.Text
+        raw["location"] = map[string]interface{}{
+            "title": m.Title,
+            "latitude": m.Latitude,
+            "longitude": m.Longitude,
+        }
+    case *linebotapi.StickerMessage:
+        raw["contentType"] = linebotapi.ContentTypeSticker
+        raw["contentMetadata"] = map[string]string{
+            "STKID": m.StickerId,
+            "STKPKGID": m.StickerPackageId,
+            "STKVER": m.StickerVersion,
+        }
+    case *linebotapi.ContactMessage:
+        raw["contentType"] = linebotapi.ContentTypeContact
+        raw["contentMetadata"] = map[string]string{
+            "mid": m.Mid,
+            "displayName": m.DisplayName,
+        }
+    case *linebotapi.AddedOperation:
+        eventType = EventTypeReceivingOperation
+        raw["opType"] = linebotapi.OpTypeAdded
+    case *linebotapi.BlockedOperation:
+        eventType = EventTypeReceivingOperation
+        raw["opType"] = linebotapi.OpTypeBlocked
+    default:
+        return nil, fmt.Errorf("linebotapitest: unsupported content %T", content)
+    }
+
+    return map[string]interface{}{
+        "content": raw,
+        "createdTime": header.CreatedTime,
+        "eventType": eventType,
+        "from": "u206d25c2ea6bd87c17655609a1c37cb8",
+        "fromChannel": 1341301815,
+        "id": fmt.Sprintf("WB1519-%010d", index + 1),
+        "to": header.To,
+        "toChannel": cred.ChannelId,
+    }, nil
+}
@@ -0,0 +1,84 @@
+package linebotapitest
+
+import (
+    "testing"
+
+    "reflect"
+    "net/http"
+    "net/http/httptest"
+
+    "github.com/mokejp/linebotapi"
+)
+
+func Test_NewWebhookRequest(t *testing.T) {
+    cred := &linebotapi.Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0123456789abcdef",
+        Mid: "u0123456789abcdef0123456789abcdef",
+    }
+    contents := []linebotapi.Content{
+        &linebotapi.TextMessage{Text: "hello"},
+        &linebotapi.ImageMessage{},
+        &linebotapi.AudioMessage{AudioLength: 1200},
+        &linebotapi.LocationMessage{Text: "here", Title: "Tokyo", Latitude: 35.6, Longitude: 139.7},
+        &linebotapi.StickerMessage{StickerId: "3", StickerPackageId: "332", StickerVersion: "100"},
+        &linebotapi.ContactMessage{Mid: "u1", DisplayName: "one"},
+        &linebotapi.AddedOperation{ContentHeader: linebotapi.ContentHeader{From: "u2"}},
+        &linebotapi.BlockedOperation{},
+    }
+    req, err := NewWebhookRequest(cred, "http://localhost/callback", contents...)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    events, err := linebotapi.ParseRequest(req, cred)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(events) != len(contents) {
+        t.Errorf("excepted: %d, actual: %d", len(contents), len(events))
+        return
+    }
+    for i, event := range events {
+        if reflect.TypeOf(event.Content) != reflect.TypeOf(contents[i]) {
+            t.Errorf("%d: excepted: %T, actual: %T", i, contents[i], event.Content)
+        }
+    }
+    if m := events[3].Content.(*linebotapi.LocationMessage); m.Longitude != 139.7 || m.To[0] != cred.Mid {
+        t.Errorf("unexpected content: %#v", m)
+    }
+    if m := events[6].Content.(*linebotapi.AddedOperation); m.From != "u2" {
+        t.Errorf("excepted: 'u2', actual: '%s'", m.From)
+    }
+}
+
+func Test_NewTamperedWebhookRequest(t *testing.T) {
+    cred := &linebotapi.Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0123456789abcdef",
+        Mid: "u0123456789abcdef0123456789abcdef",
+    }
+    handler := linebotapi.NewWebhookHandler(cred)
+    tests := []struct {
+        tamper Tamper
+        code int
+    }{
+        {TamperNone, http.StatusOK},
+        {TamperBadSignature, http.StatusForbidden},
+        {TamperMissingSignature, http.StatusForbidden},
+        {TamperMalformedSignature, http.StatusForbidden},
+    }
+    for _, test := range tests {
+        req, err := NewTamperedWebhookRequest(cred, "http://localhost/callback", test.tamper, &linebotapi.TextMessage{Text: "hello"})
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        w := httptest.NewRecorder()
+        handler.ServeHTTP(w, req)
+        if w.Code != test.code {
+            t.Errorf("%d: excepted: %d, actual: %d", test.tamper, test.code, w.Code)
+        }
+    }
+}
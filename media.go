@@ -0,0 +1,177 @@
+package linebotapi
+
+import (
+    "io"
+    "math"
+    "time"
+    "bufio"
+    "errors"
+    "image"
+    "net/http"
+    "io/ioutil"
+    "encoding/binary"
+
+    _ "image/jpeg"
+    _ "image/png"
+)
+
+// MediaInfo describes downloaded message content. Width and Height are set
+// for JPEG and PNG images, Duration for MP4 video and M4A audio that carry a
+// movie header.
+type MediaInfo struct {
+    MIMEType string
+    Size int64
+    Width int
+    Height int
+    Duration time.Duration
+}
+
+var errInvalidMP4 = errors.New("linebotapi: invalid MP4 box")
+
+type countingReader struct {
+    r io.Reader
+    n int64
+}
+func (c *countingReader) Read(p []byte) (int, error) {
+    n, err := c.r.Read(p)
+    c.n += int64(n)
+    return n, err
+}
+
+// SniffMedia reads r to the end and returns what it could learn about the
+// media from its content. Only headers are parsed, so the content is not
+// held in memory. Bound r, e.g. with DownloadMessageContent's maxBytes or
+// io.LimitReader, when its size is not trusted.
+func SniffMedia(r io.Reader) (*MediaInfo, error) {
+    counter := &countingReader{r: r}
+    br := bufio.NewReaderSize(counter, 512)
+    head, err := br.Peek(512)
+    if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+        return nil, err
+    }
+
+    info := &MediaInfo{}
+    if brand, ok := mp4Brand(head); ok {
+        info.MIMEType = "video/mp4"
+        if brand == "M4A " || brand == "M4B " {
+            info.MIMEType = "audio/x-m4a"
+        }
+        info.Duration, err = mp4Duration(br)
+        if err != nil {
+            return nil, err
+        }
+    } else {
+        info.MIMEType = http.DetectContentType(head)
+        if info.MIMEType == "image/jpeg" || info.MIMEType == "image/png" {
+            config, _, err := image.DecodeConfig(br)
+            if err != nil {
+                return nil, err
+            }
+            info.Width, info.Height = config.Width, config.Height
+        }
+    }
+
+    _, err = io.Copy(ioutil.Discard, br)
+    if err != nil {
+        return nil, err
+    }
+    info.Size = counter.n
+    return info, nil
+}
+
+// mp4Brand returns the major brand of an ISO base media file.
+func mp4Brand(head []byte) (string, bool) {
+    if len(head) < 12 || string(head[4:8]) != "ftyp" {
+        return "", false
+    }
+    return string(head[8:12]), true
+}
+
+// mp4Duration walks the top-level boxes of r and returns the duration from
+// the movie header, skipping over media data without buffering it.
+func mp4Duration(r io.Reader) (time.Duration, error) {
+    header := make([]byte, 16)
+    for {
+        _, err := io.ReadFull(r, header[:8])
+        if err == io.EOF {
+            return 0, nil
+        }
+        if err != nil {
+            return 0, err
+        }
+        size := int64(binary.BigEndian.Uint32(header[:4]))
+        boxType := string(header[4:8])
+        headerSize := int64(8)
+        if size == 1 {
+            _, err = io.ReadFull(r, header[8:16])
+            if err != nil {
+                return 0, err
+            }
+            size = int64(binary.BigEndian.Uint64(header[8:16]))
+            headerSize = 16
+        }
+        if size == 0 {
+            // box extends to the end of the file
+            return 0, nil
+        }
+        if size < headerSize {
+            return 0, errInvalidMP4
+        }
+
+        if boxType != "moov" {
+            _, err = io.CopyN(ioutil.Discard, r, size - headerSize)
+            if err != nil {
+                return 0, err
+            }
+            continue
+        }
+        if size - headerSize > 64 << 20 {
+            return 0, errInvalidMP4
+        }
+        moov := make([]byte, size - headerSize)
+        _, err = io.ReadFull(r, moov)
+        if err != nil {
+            return 0, err
+        }
+        return mvhdDuration(moov)
+    }
+}
+
+func mvhdDuration(moov []byte) (time.Duration, error) {
+    for len(moov) >= 8 {
+        size := int(binary.BigEndian.Uint32(moov[:4]))
+        if size < 8 || size > len(moov) {
+            return 0, errInvalidMP4
+        }
+        if string(moov[4:8]) != "mvhd" {
+            moov = moov[size:]
+            continue
+        }
+        box := moov[8:size]
+        var timescale, duration uint64
+        switch {
+        case len(box) >= 20 && box[0] == 0:
+            timescale = uint64(binary.BigEndian.Uint32(box[12:16]))
+            duration = uint64(binary.BigEndian.Uint32(box[16:20]))
+        case len(box) >= 32 && box[0] == 1:
+            timescale = uint64(binary.BigEndian.Uint32(box[20:24]))
+            duration = binary.BigEndian.Uint64(box[24:32])
+        default:
+            return 0, errInvalidMP4
+        }
+        if timescale == 0 {
+            return 0, errInvalidMP4
+        }
+        // the duration comes from the upload, so keep it from overflowing
+        seconds, rem := duration / timescale, duration % timescale
+        if seconds > math.MaxInt64 / uint64(time.Second) {
+            return 0, errInvalidMP4
+        }
+        d := seconds * uint64(time.Second) + rem * uint64(time.Second) / timescale
+        if d > math.MaxInt64 {
+            return 0, errInvalidMP4
+        }
+        return time.Duration(d), nil
+    }
+    return 0, nil
+}
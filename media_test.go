@@ -0,0 +1,117 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "time"
+    "bytes"
+    "image"
+    "image/png"
+    "image/jpeg"
+    "encoding/binary"
+)
+
+func mp4Box(boxType string, payload ...[]byte) []byte {
+    body := bytes.Join(payload, nil)
+    box := make([]byte, 8, 8 + len(body))
+    binary.BigEndian.PutUint32(box, uint32(8 + len(body)))
+    copy(box[4:], boxType)
+    return append(box, body...)
+}
+
+func newMP4(brand string, timescale uint32, duration uint32) []byte {
+    ftyp := mp4Box("ftyp", []byte(brand), make([]byte, 4), []byte("isom"))
+    mvhd := make([]byte, 100)
+    binary.BigEndian.PutUint32(mvhd[12:], timescale)
+    binary.BigEndian.PutUint32(mvhd[16:], duration)
+    // moov after mdat, as written by most encoders
+    mdat := mp4Box("mdat", make([]byte, 4096))
+    moov := mp4Box("moov", mp4Box("mvhd", mvhd))
+    return bytes.Join([][]byte{ftyp, mdat, moov}, nil)
+}
+
+func Test_SniffMedia_Image(t *testing.T) {
+    img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+    var buf bytes.Buffer
+    png.Encode(&buf, img)
+    size := int64(buf.Len())
+    info, err := SniffMedia(&buf)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if info.MIMEType != "image/png" || info.Width != 40 || info.Height != 30 || info.Size != size {
+        t.Errorf("unexpected info: %#v", info)
+    }
+
+    buf.Reset()
+    jpeg.Encode(&buf, img, nil)
+    info, err = SniffMedia(&buf)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if info.MIMEType != "image/jpeg" || info.Width != 40 || info.Height != 30 {
+        t.Errorf("unexpected info: %#v", info)
+    }
+}
+
+func Test_SniffMedia_MP4(t *testing.T) {
+    data := newMP4("mp42", 1000, 12500)
+    info, err := SniffMedia(bytes.NewReader(data))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if info.MIMEType != "video/mp4" || info.Duration != 12500 * time.Millisecond || info.Size != int64(len(data)) {
+        t.Errorf("unexpected info: %#v", info)
+    }
+
+    info, err = SniffMedia(bytes.NewReader(newMP4("M4A ", 44100, 44100 * 3)))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if info.MIMEType != "audio/x-m4a" || info.Duration != 3 * time.Second {
+        t.Errorf("unexpected info: %#v", info)
+    }
+}
+
+func Test_SniffMedia_Invalid(t *testing.T) {
+    data := newMP4("mp42", 1000, 1000)
+    // truncate inside mdat
+    _, err := SniffMedia(bytes.NewReader(data[:1024]))
+    if err == nil {
+        t.Error("err is nil")
+    }
+
+    info, err := SniffMedia(bytes.NewReader([]byte("hello")))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if info.MIMEType != "text/plain; charset=utf-8" || info.Size != 5 {
+        t.Errorf("unexpected info: %#v", info)
+    }
+}
+
+func Test_MvhdDuration_Version1(t *testing.T) {
+    newMoov := func(timescale uint32, duration uint64) []byte {
+        mvhd := make([]byte, 112)
+        mvhd[0] = 1
+        binary.BigEndian.PutUint32(mvhd[20:], timescale)
+        binary.BigEndian.PutUint64(mvhd[24:], duration)
+        return mp4Box("mvhd", mvhd)
+    }
+    d, err := mvhdDuration(newMoov(1000, 1 << 40 + 500))
+    if err != nil || d != time.Duration(1 << 40) * time.Millisecond + 500 * time.Millisecond {
+        t.Errorf("unexpected duration: %v %v", d, err)
+    }
+    // crafted durations that overflowed time.Duration
+    for _, duration := range []uint64{1 << 62 + 12345, 0xffffffffffffff00} {
+        d, err := mvhdDuration(newMoov(1000, duration))
+        if err != errInvalidMP4 {
+            t.Errorf("%d: excepted: %v, actual: %v %v", duration, errInvalidMP4, d, err)
+        }
+    }
+}
@@ -0,0 +1,264 @@
+package linebotapi
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "sync"
+    "time"
+    "bytes"
+    "errors"
+    "strconv"
+    "strings"
+    "io/ioutil"
+    "net/url"
+    "net/http"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/base64"
+)
+
+var ErrMediaNotHosted = errors.New("linebotapi: media not hosted")
+
+// HostedMedia holds the URLs of content added to a MediaHost. PreviewURL is
+// set for images, which get a generated preview, and for videos added with a
+// preview image.
+type HostedMedia struct {
+    Id string
+    ContentType string
+    OriginalURL string
+    PreviewURL string
+    Expires time.Time
+}
+
+// MediaHost is an http.Handler serving content for image, video and audio
+// messages, whose URLs must be reachable by the LINE platform. Content is
+// identified by the SHA-256 of what is served, which for images is the
+// prepared JPEG rather than the input, so the path of a URL never changes;
+// the query carries an expiry time and an HMAC signature made with Secret.
+//
+// Mount the handler at BaseURL:
+//
+//     host := linebotapi.NewMediaHost("https://bot.example.com/media/", secret, 0)
+//     http.Handle("/media/", host)
+type MediaHost struct {
+    BaseURL string
+    Secret []byte
+    // TTL is how long signed URLs stay valid and defaults to 7 days.
+    TTL time.Duration
+
+    mu sync.RWMutex
+    items map[string]*hostedItem
+}
+
+type hostedItem struct {
+    contentType string
+    data []byte
+    // path is set instead of data for content added with AddFile
+    path string
+    modTime time.Time
+    preview []byte
+}
+
+func NewMediaHost(baseURL string, secret []byte, ttl time.Duration) *MediaHost {
+    return &MediaHost{
+        BaseURL: baseURL,
+        Secret: secret,
+        TTL: ttl,
+        items: make(map[string]*hostedItem),
+    }
+}
+
+// Add hosts data. The content type is sniffed from data; JPEG and PNG images
+// are passed through PrepareImage and served as JPEG with a preview.
+func (h *MediaHost) Add(data []byte) (*HostedMedia, error) {
+    return h.add("", &hostedItem{
+        data: data,
+        modTime: time.Now(),
+    })
+}
+
+// AddVideo hosts a video together with the preview image NewMessageVideo
+// requires.
+func (h *MediaHost) AddVideo(data, preview []byte) (*HostedMedia, error) {
+    return h.add("", &hostedItem{
+        contentType: "video/mp4",
+        data: data,
+        modTime: time.Now(),
+        preview: preview,
+    })
+}
+
+// AddFile hosts the file at path. Images are read into memory and prepared
+// like in Add; other files are read once to hash them and are served from
+// disk.
+func (h *MediaHost) AddFile(path string) (*HostedMedia, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    stat, err := f.Stat()
+    if err != nil {
+        return nil, err
+    }
+    head := make([]byte, 512)
+    n, err := io.ReadFull(f, head)
+    if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+        return nil, err
+    }
+    head = head[:n]
+    r := io.MultiReader(bytes.NewReader(head), f)
+
+    if isPreviewable(http.DetectContentType(head)) {
+        data, err := ioutil.ReadAll(r)
+        if err != nil {
+            return nil, err
+        }
+        return h.add("", &hostedItem{
+            data: data,
+            modTime: stat.ModTime(),
+        })
+    }
+    hash := sha256.New()
+    info, err := SniffMedia(io.TeeReader(r, hash))
+    if err != nil {
+        return nil, err
+    }
+    return h.add(hex.EncodeToString(hash.Sum(nil)), &hostedItem{
+        contentType: info.MIMEType,
+        path: path,
+        modTime: stat.ModTime(),
+    })
+}
+
+func isPreviewable(contentType string) bool {
+    return contentType == "image/jpeg" || contentType == "image/png"
+}
+
+// add replaces images with the result of PrepareImage, so they are served
+// from memory. When id is empty it is the SHA-256 of the data served.
+func (h *MediaHost) add(id string, item *hostedItem) (*HostedMedia, error) {
+    if item.contentType == "" {
+        info, err := SniffMedia(bytes.NewReader(item.data))
+        if err != nil {
+            return nil, err
+        }
+        item.contentType = info.MIMEType
+    }
+    if item.preview == nil && isPreviewable(item.contentType) {
+        prepared, err := PrepareImage(item.data)
+        if err != nil {
+            return nil, err
+        }
+        item.contentType = "image/jpeg"
+        item.data = prepared.Original
+        item.preview = prepared.Preview
+    }
+    if id == "" {
+        sum := sha256.Sum256(item.data)
+        id = hex.EncodeToString(sum[:])
+    }
+
+    h.mu.Lock()
+    if h.items == nil {
+        h.items = make(map[string]*hostedItem)
+    }
+    h.items[id] = item
+    h.mu.Unlock()
+    return h.URLs(id)
+}
+
+// URLs returns freshly signed URLs for content added earlier.
+func (h *MediaHost) URLs(id string) (*HostedMedia, error) {
+    h.mu.RLock()
+    item, exists := h.items[id]
+    h.mu.RUnlock()
+    if !exists {
+        return nil, ErrMediaNotHosted
+    }
+
+    ttl := h.TTL
+    if ttl <= 0 {
+        ttl = 7 * 24 * time.Hour
+    }
+    expires := time.Now().Add(ttl).Truncate(time.Second)
+    media := &HostedMedia{
+        Id: id,
+        ContentType: item.contentType,
+        OriginalURL: h.signedURL(id, expires),
+        Expires: expires,
+    }
+    if item.preview != nil {
+        media.PreviewURL = h.signedURL(id + "-preview", expires)
+    }
+    return media, nil
+}
+
+func (h *MediaHost) Remove(id string) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    delete(h.items, id)
+}
+
+func (h *MediaHost) signedURL(name string, expires time.Time) string {
+    query := url.Values{}
+    query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+    query.Set("signature", h.sign(name, expires.Unix()))
+    return strings.TrimSuffix(h.BaseURL, "/") + "/" + name + "?" + query.Encode()
+}
+
+func (h *MediaHost) sign(name string, expires int64) string {
+    mac := hmac.New(sha256.New, h.Secret)
+    fmt.Fprintf(mac, "%s\n%d", name, expires)
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP serves the content named by the last element of the request
+// path. It responds with 403 when the signature is invalid or expired and
+// 404 when the content is unknown.
+func (h *MediaHost) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.Method != "GET" && r.Method != "HEAD" {
+        w.Header().Set("Allow", "GET, HEAD")
+        http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+        return
+    }
+
+    name := r.URL.Path[strings.LastIndex(r.URL.Path, "/") + 1:]
+    query := r.URL.Query()
+    expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+    if err != nil || time.Now().Unix() > expires ||
+        !hmac.Equal([]byte(query.Get("signature")), []byte(h.sign(name, expires))) {
+        http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+        return
+    }
+
+    id := strings.TrimSuffix(name, "-preview")
+    h.mu.RLock()
+    item, exists := h.items[id]
+    h.mu.RUnlock()
+    if !exists || (id != name && item.preview == nil) {
+        http.NotFound(w, r)
+        return
+    }
+
+    w.Header().Set("Cache-Control", "private, max-age=" + strconv.FormatInt(expires - time.Now().Unix(), 10))
+    if id != name {
+        w.Header().Set("Content-Type", "image/jpeg")
+        http.ServeContent(w, r, "", item.modTime, bytes.NewReader(item.preview))
+        return
+    }
+    w.Header().Set("Content-Type", item.contentType)
+    if item.path == "" {
+        http.ServeContent(w, r, "", item.modTime, bytes.NewReader(item.data))
+        return
+    }
+    f, err := os.Open(item.path)
+    if err != nil {
+        http.NotFound(w, r)
+        return
+    }
+    defer f.Close()
+    http.ServeContent(w, r, "", item.modTime, f)
+}
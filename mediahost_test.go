@@ -0,0 +1,129 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "time"
+    "bytes"
+    "image"
+    "strings"
+    "image/png"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+    "crypto/sha256"
+    "encoding/hex"
+)
+
+func Test_MediaHost_Image(t *testing.T) {
+    host := NewMediaHost("https://bot.example.com/media/", []byte("secret"), time.Hour)
+    var buf bytes.Buffer
+    png.Encode(&buf, image.NewGray(image.Rect(0, 0, 480, 480)))
+    media, err := host.Add(buf.Bytes())
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if media.ContentType != "image/jpeg" || media.PreviewURL == "" {
+        t.Errorf("unexpected media: %#v", media)
+    }
+    if !strings.HasPrefix(media.OriginalURL, "https://bot.example.com/media/" + media.Id + "?") {
+        t.Errorf("unexpected url: %s", media.OriginalURL)
+    }
+
+    tests := []struct {
+        url string
+        code int
+        contentType string
+    }{
+        {media.OriginalURL, http.StatusOK, "image/jpeg"},
+        {media.PreviewURL, http.StatusOK, "image/jpeg"},
+        {strings.Replace(media.OriginalURL, "expires=", "expires=1", 1), http.StatusForbidden, ""},
+        {"https://bot.example.com/media/" + media.Id, http.StatusForbidden, ""},
+    }
+    for i, test := range tests {
+        w := httptest.NewRecorder()
+        host.ServeHTTP(w, httptest.NewRequest("GET", test.url, nil))
+        if w.Code != test.code {
+            t.Errorf("%d: excepted: %d, actual: %d", i, test.code, w.Code)
+        }
+        if test.contentType != "" && w.Header().Get("Content-Type") != test.contentType {
+            t.Errorf("%d: excepted: %s, actual: %s", i, test.contentType, w.Header().Get("Content-Type"))
+        }
+    }
+
+    host.Remove(media.Id)
+    w := httptest.NewRecorder()
+    host.ServeHTTP(w, httptest.NewRequest("GET", media.OriginalURL, nil))
+    if w.Code != http.StatusNotFound {
+        t.Errorf("excepted: 404, actual: %d", w.Code)
+    }
+}
+
+func Test_MediaHost_Expired(t *testing.T) {
+    host := NewMediaHost("https://bot.example.com/media", []byte("secret"), time.Hour)
+    media, err := host.Add([]byte("audio"))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if media.PreviewURL != "" {
+        t.Errorf("unexpected preview: %s", media.PreviewURL)
+    }
+    expired := host.signedURL(media.Id, time.Now().Add(-time.Second))
+    w := httptest.NewRecorder()
+    host.ServeHTTP(w, httptest.NewRequest("GET", expired, nil))
+    if w.Code != http.StatusForbidden {
+        t.Errorf("excepted: 403, actual: %d", w.Code)
+    }
+}
+
+func Test_MediaHost_AddFile(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "video.mp4")
+    ioutil.WriteFile(path, []byte("\x00\x00\x00\x10ftypmp42\x00\x00\x00\x00"), 0644)
+    host := NewMediaHost("https://bot.example.com/media", []byte("secret"), 0)
+    media, err := host.AddFile(path)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if media.ContentType != "video/mp4" || media.Expires.Before(time.Now().Add(6 * 24 * time.Hour)) {
+        t.Errorf("unexpected media: %#v", media)
+    }
+    w := httptest.NewRecorder()
+    host.ServeHTTP(w, httptest.NewRequest("GET", media.OriginalURL, nil))
+    if w.Code != http.StatusOK || w.Body.Len() != 16 {
+        t.Errorf("unexpected response: %d %d", w.Code, w.Body.Len())
+    }
+}
+
+func Test_MediaHost_ImageId(t *testing.T) {
+    var buf bytes.Buffer
+    png.Encode(&buf, image.NewGray(image.Rect(0, 0, 480, 480)))
+    path := filepath.Join(t.TempDir(), "image.png")
+    ioutil.WriteFile(path, buf.Bytes(), 0644)
+
+    host := NewMediaHost("https://bot.example.com/media", []byte("secret"), 0)
+    media, err := host.AddFile(path)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    w := httptest.NewRecorder()
+    host.ServeHTTP(w, httptest.NewRequest("GET", media.OriginalURL, nil))
+    sum := sha256.Sum256(w.Body.Bytes())
+    if media.ContentType != "image/jpeg" || media.Id != hex.EncodeToString(sum[:]) {
+        t.Errorf("unexpected media: %#v", media)
+    }
+
+    // the same image added from memory is the same entry
+    added, err := host.Add(buf.Bytes())
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if added.Id != media.Id {
+        t.Errorf("excepted: %s, actual: %s", media.Id, added.Id)
+    }
+}
@@ -0,0 +1,250 @@
+package linebotapi
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "sync"
+    "bytes"
+    "errors"
+    "context"
+    "strings"
+    "io/ioutil"
+    "crypto/sha256"
+    "path/filepath"
+    "encoding/hex"
+    "encoding/json"
+)
+
+var ErrMediaNotFound = errors.New("linebotapi: media not found")
+
+// StoredMedia describes content kept in a MediaStore. Hash is the hex encoded
+// SHA-256 of the content.
+type StoredMedia struct {
+    MessageId string `json:"messageId"`
+    Hash string `json:"hash"`
+    ContentType string `json:"contentType"`
+    Size int64 `json:"size"`
+}
+
+// MediaStore keeps message content by message id. Implementations store
+// content addressed by its hash, so identical content sent in several
+// messages is only kept once.
+type MediaStore interface {
+    // Put stores everything read from r under messageId. Nothing is stored
+    // when reading r fails.
+    Put(ctx context.Context, messageId, contentType string, r io.Reader) (*StoredMedia, error)
+    // Stat returns ErrMediaNotFound when messageId is not stored.
+    Stat(ctx context.Context, messageId string) (*StoredMedia, error)
+    // Open returns ErrMediaNotFound when messageId is not stored. The caller
+    // must close the returned reader.
+    Open(ctx context.Context, messageId string) (*StoredMedia, io.ReadCloser, error)
+}
+
+// StoreMessageContent downloads the content of a message into store. A
+// maxBytes of 0 or less does not limit the size; otherwise
+// ErrContentTooLarge is returned and nothing is stored when the content is
+// larger.
+func (c *Client) StoreMessageContent(ctx context.Context, store MediaStore, messageId string, maxBytes int64) (*StoredMedia, error) {
+    data, err := c.getContent(ctx, fmt.Sprintf("/v1/bot/message/%s/content", messageId))
+    if err != nil {
+        return nil, err
+    }
+    defer data.Reader.Close()
+
+    if maxBytes > 0 && data.ContentLength > maxBytes {
+        return nil, ErrContentTooLarge
+    }
+    var reader io.Reader = data.Reader
+    if maxBytes > 0 {
+        reader = &sizeLimitedReader{r: data.Reader, remaining: maxBytes}
+    }
+    return store.Put(ctx, messageId, data.ContentType, reader)
+}
+
+// MemoryMediaStore is a MediaStore that keeps content in memory. Nothing is
+// ever evicted, so it is meant for tests and short-lived processes; use
+// FileMediaStore or a store of your own otherwise.
+type MemoryMediaStore struct {
+    mu sync.RWMutex
+    blobs map[string][]byte
+    index map[string]StoredMedia
+}
+
+func NewMemoryMediaStore() *MemoryMediaStore {
+    return &MemoryMediaStore{
+        blobs: make(map[string][]byte),
+        index: make(map[string]StoredMedia),
+    }
+}
+
+func (s *MemoryMediaStore) Put(ctx context.Context, messageId, contentType string, r io.Reader) (*StoredMedia, error) {
+    data, err := ioutil.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+    sum := sha256.Sum256(data)
+    media := StoredMedia{
+        MessageId: messageId,
+        Hash: hex.EncodeToString(sum[:]),
+        ContentType: contentType,
+        Size: int64(len(data)),
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if _, exists := s.blobs[media.Hash]; !exists {
+        s.blobs[media.Hash] = data
+    }
+    s.index[messageId] = media
+    return &media, nil
+}
+
+func (s *MemoryMediaStore) Stat(ctx context.Context, messageId string) (*StoredMedia, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    media, exists := s.index[messageId]
+    if !exists {
+        return nil, ErrMediaNotFound
+    }
+    return &media, nil
+}
+
+func (s *MemoryMediaStore) Open(ctx context.Context, messageId string) (*StoredMedia, io.ReadCloser, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    media, exists := s.index[messageId]
+    if !exists {
+        return nil, nil, ErrMediaNotFound
+    }
+    return &media, ioutil.NopCloser(bytes.NewReader(s.blobs[media.Hash])), nil
+}
+
+// FileMediaStore is a MediaStore that keeps content in a directory. Content
+// is written to blobs/<hash> and the index to messages/<messageId>.json.
+type FileMediaStore struct {
+    Dir string
+}
+
+// NewFileMediaStore creates the directories of the store below dir.
+func NewFileMediaStore(dir string) (*FileMediaStore, error) {
+    for _, sub := range []string{"blobs", "messages"} {
+        err := os.MkdirAll(filepath.Join(dir, sub), 0755)
+        if err != nil {
+            return nil, err
+        }
+    }
+    return &FileMediaStore{Dir: dir}, nil
+}
+
+func (s *FileMediaStore) Put(ctx context.Context, messageId, contentType string, r io.Reader) (*StoredMedia, error) {
+    indexPath, err := s.indexPath(messageId)
+    if err != nil {
+        return nil, err
+    }
+
+    blobs := filepath.Join(s.Dir, "blobs")
+    f, err := ioutil.TempFile(blobs, ".blob.*")
+    if err != nil {
+        return nil, err
+    }
+    defer os.Remove(f.Name())
+    hash := sha256.New()
+    size, err := io.Copy(io.MultiWriter(f, hash), r)
+    if err == nil {
+        err = f.Chmod(0644)
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    media := &StoredMedia{
+        MessageId: messageId,
+        Hash: hex.EncodeToString(hash.Sum(nil)),
+        ContentType: contentType,
+        Size: size,
+    }
+    blobPath := filepath.Join(blobs, media.Hash)
+    if _, err := os.Stat(blobPath); os.IsNotExist(err) {
+        err = os.Rename(f.Name(), blobPath)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    data, err := json.Marshal(media)
+    if err != nil {
+        return nil, err
+    }
+    err = writeFileAtomic(indexPath, data)
+    if err != nil {
+        return nil, err
+    }
+    return media, nil
+}
+
+func (s *FileMediaStore) Stat(ctx context.Context, messageId string) (*StoredMedia, error) {
+    indexPath, err := s.indexPath(messageId)
+    if err != nil {
+        return nil, err
+    }
+    data, err := ioutil.ReadFile(indexPath)
+    if os.IsNotExist(err) {
+        return nil, ErrMediaNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    var media StoredMedia
+    err = json.Unmarshal(data, &media)
+    if err != nil {
+        return nil, err
+    }
+    return &media, nil
+}
+
+func (s *FileMediaStore) Open(ctx context.Context, messageId string) (*StoredMedia, io.ReadCloser, error) {
+    media, err := s.Stat(ctx, messageId)
+    if err != nil {
+        return nil, nil, err
+    }
+    f, err := os.Open(filepath.Join(s.Dir, "blobs", media.Hash))
+    if os.IsNotExist(err) {
+        return nil, nil, ErrMediaNotFound
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return media, f, nil
+}
+
+func (s *FileMediaStore) indexPath(messageId string) (string, error) {
+    if messageId == "" || messageId == "." || messageId == ".." || strings.ContainsAny(messageId, `/\`) {
+        return "", fmt.Errorf("linebotapi: invalid message id '%s'", messageId)
+    }
+    return filepath.Join(s.Dir, "messages", messageId + ".json"), nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+    f, err := ioutil.TempFile(filepath.Dir(path), "." + filepath.Base(path) + ".*")
+    if err != nil {
+        return err
+    }
+    _, err = f.Write(data)
+    if err == nil {
+        err = f.Chmod(0644)
+    }
+    if cerr := f.Close(); err == nil {
+        err = cerr
+    }
+    if err == nil {
+        err = os.Rename(f.Name(), path)
+    }
+    if err != nil {
+        os.Remove(f.Name())
+    }
+    return err
+}
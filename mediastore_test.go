@@ -0,0 +1,138 @@
+package linebotapi_test
+
+import (
+    "testing"
+
+    "context"
+    "strings"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+
+    "github.com/mokejp/linebotapi"
+    "github.com/mokejp/linebotapi/linebotapitest"
+)
+
+func testMediaStore(t *testing.T, store linebotapi.MediaStore) {
+    ctx := context.Background()
+    _, err := store.Stat(ctx, "100")
+    if err != linebotapi.ErrMediaNotFound {
+        t.Errorf("excepted: ErrMediaNotFound, actual: %v", err)
+    }
+
+    first, err := store.Put(ctx, "100", "image/jpeg", strings.NewReader("image"))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    second, err := store.Put(ctx, "101", "image/jpeg", strings.NewReader("image"))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if first.Hash != second.Hash || first.Size != 5 || second.MessageId != "101" {
+        t.Errorf("unexpected media: %#v %#v", first, second)
+    }
+
+    media, reader, err := store.Open(ctx, "101")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    data, _ := ioutil.ReadAll(reader)
+    reader.Close()
+    if string(data) != "image" || media.ContentType != "image/jpeg" {
+        t.Errorf("unexpected content: %q %#v", data, media)
+    }
+}
+
+func Test_MemoryMediaStore(t *testing.T) {
+    testMediaStore(t, linebotapi.NewMemoryMediaStore())
+}
+
+func Test_FileMediaStore(t *testing.T) {
+    dir := t.TempDir()
+    store, err := linebotapi.NewFileMediaStore(dir)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    testMediaStore(t, store)
+
+    blobs, _ := ioutil.ReadDir(dir + "/blobs")
+    if len(blobs) != 1 {
+        t.Errorf("excepted: 1, actual: %d", len(blobs))
+    }
+    _, err = store.Put(context.Background(), "../100", "image/jpeg", strings.NewReader("image"))
+    if err == nil {
+        t.Error("err is nil")
+    }
+}
+
+func Test_StoreMessageContent_TooLarge(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("0123456789"))
+    store := linebotapi.NewMemoryMediaStore()
+
+    _, err := server.APIClient().StoreMessageContent(context.Background(), store, "100", 9)
+    if err != linebotapi.ErrContentTooLarge {
+        t.Errorf("excepted: ErrContentTooLarge, actual: %v", err)
+    }
+    _, err = store.Stat(context.Background(), "100")
+    if err != linebotapi.ErrMediaNotFound {
+        t.Errorf("excepted: ErrMediaNotFound, actual: %v", err)
+    }
+}
+
+func Test_WebhookHandler_StoreMedia(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.SetContent("100", "image/jpeg", []byte("image"))
+    store := linebotapi.NewMemoryMediaStore()
+
+    handler := linebotapi.NewWebhookHandler(server.Credential)
+    handler.Client = server.APIClient()
+    handler.MediaStore = store
+    var stored []string
+    handler.OnImage(func(r *http.Request, m *linebotapi.ImageMessage) error {
+        media, err := store.Stat(r.Context(), m.Id)
+        if err != nil {
+            return err
+        }
+        stored = append(stored, media.Hash)
+        return nil
+    })
+
+    for i := 0; i < 2; i++ {
+        req, err := linebotapitest.NewWebhookRequest(server.Credential, "/callback", &linebotapi.ImageMessage{
+            ContentHeader: linebotapi.ContentHeader{Id: "100"},
+        })
+        if err != nil {
+            t.Error(err)
+            return
+        }
+        w := httptest.NewRecorder()
+        handler.ServeHTTP(w, req)
+        if w.Code != http.StatusOK {
+            t.Errorf("excepted: 200, actual: %d", w.Code)
+        }
+    }
+    if len(stored) != 2 {
+        t.Errorf("excepted: 2, actual: %d", len(stored))
+    }
+    // redelivered events are not downloaded again
+    if n := server.Requests("/v1/bot/message/{id}/content"); n != 1 {
+        t.Errorf("excepted: 1, actual: %d", n)
+    }
+
+    req, _ := linebotapitest.NewWebhookRequest(server.Credential, "/callback", &linebotapi.ImageMessage{
+        ContentHeader: linebotapi.ContentHeader{Id: "missing"},
+    })
+    w := httptest.NewRecorder()
+    handler.ServeHTTP(w, req)
+    if w.Code != http.StatusInternalServerError || len(stored) != 2 {
+        t.Errorf("unexpected result: %d %d", w.Code, len(stored))
+    }
+}
+
@@ -0,0 +1,141 @@
+package linebotapi
+
+import (
+    "bytes"
+    "errors"
+    "image"
+    "image/jpeg"
+)
+
+// Size limits of the images of an image message.
+const (
+    MaxImageWidth = 1024
+    MaxImageHeight = 1024
+    MaxPreviewWidth = 240
+    MaxPreviewHeight = 240
+)
+
+// MaxImagePixels limits the images PrepareImage decodes, checked against the
+// size in the image header before any pixels are allocated.
+const MaxImagePixels = 25000000
+
+const jpegQuality = 85
+
+var ErrImageTooLarge = errors.New("linebotapi: image exceeds pixel limit")
+
+// PreparedImage holds JPEG images ready to upload for an image message.
+type PreparedImage struct {
+    Original []byte
+    Preview []byte
+    Width int
+    Height int
+}
+
+// PrepareImage takes a JPEG or PNG image and returns it as a JPEG that fits
+// MaxImageWidth x MaxImageHeight together with a preview that fits
+// MaxPreviewWidth x MaxPreviewHeight. A JPEG whose dimensions already fit is
+// kept as is, whatever its file size; only the dimensions decide whether an
+// image is re-encoded. Images with more than MaxImagePixels pixels are
+// rejected with ErrImageTooLarge.
+func PrepareImage(data []byte) (*PreparedImage, error) {
+    config, _, err := image.DecodeConfig(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    if config.Width <= 0 || config.Height <= 0 || int64(config.Width) * int64(config.Height) > MaxImagePixels {
+        return nil, ErrImageTooLarge
+    }
+    img, format, err := image.Decode(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+
+    prepared := &PreparedImage{
+        Original: data,
+        Width: img.Bounds().Dx(),
+        Height: img.Bounds().Dy(),
+    }
+    if format != "jpeg" || prepared.Width > MaxImageWidth || prepared.Height > MaxImageHeight {
+        original := resizeImage(img, MaxImageWidth, MaxImageHeight)
+        prepared.Width, prepared.Height = original.Bounds().Dx(), original.Bounds().Dy()
+        prepared.Original, err = encodeJPEG(original)
+        if err != nil {
+            return nil, err
+        }
+    }
+    prepared.Preview, err = encodeJPEG(resizeImage(img, MaxPreviewWidth, MaxPreviewHeight))
+    if err != nil {
+        return nil, err
+    }
+    return prepared, nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+    var buf bytes.Buffer
+    err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+    if err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+// resizeImage scales src down to fit maxWidth x maxHeight, keeping the aspect
+// ratio, by averaging the source pixels covered by each destination pixel.
+// Transparent areas are flattened onto white since JPEG has no alpha. An
+// opaque src that already fits is returned as is; otherwise only the
+// destination image is allocated.
+func resizeImage(src image.Image, maxWidth, maxHeight int) image.Image {
+    bounds := src.Bounds()
+    width, height := bounds.Dx(), bounds.Dy()
+    dstWidth, dstHeight := width, height
+    if width <= maxWidth && height <= maxHeight {
+        if opaque, ok := src.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+            return src
+        }
+    } else {
+        dstWidth, dstHeight = maxWidth, height * maxWidth / width
+        if dstHeight > maxHeight {
+            dstWidth, dstHeight = width * maxHeight / height, maxHeight
+        }
+        if dstWidth < 1 {
+            dstWidth = 1
+        }
+        if dstHeight < 1 {
+            dstHeight = 1
+        }
+    }
+
+    dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+    for y := 0; y < dstHeight; y++ {
+        y0, y1 := y * height / dstHeight, (y + 1) * height / dstHeight
+        if y1 == y0 {
+            y1 = y0 + 1
+        }
+        for x := 0; x < dstWidth; x++ {
+            x0, x1 := x * width / dstWidth, (x + 1) * width / dstWidth
+            if x1 == x0 {
+                x1 = x0 + 1
+            }
+            var sum [4]uint64
+            for sy := y0; sy < y1; sy++ {
+                for sx := x0; sx < x1; sx++ {
+                    r, g, b, a := src.At(bounds.Min.X + sx, bounds.Min.Y + sy).RGBA()
+                    sum[0] += uint64(r)
+                    sum[1] += uint64(g)
+                    sum[2] += uint64(b)
+                    sum[3] += uint64(a)
+                }
+            }
+            n := uint64((y1 - y0) * (x1 - x0))
+            // colors are premultiplied, so adding the missing alpha puts
+            // them over white
+            white := 0xffff - sum[3] / n
+            offset := dst.PixOffset(x, y)
+            for i := 0; i < 3; i++ {
+                dst.Pix[offset + i] = uint8((sum[i] / n + white) >> 8)
+            }
+            dst.Pix[offset + 3] = 0xff
+        }
+    }
+    return dst
+}
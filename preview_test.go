@@ -0,0 +1,103 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "bytes"
+    "image"
+    "image/png"
+    "image/color"
+    "image/jpeg"
+    "hash/crc32"
+    "encoding/binary"
+)
+
+func Test_ResizeImage(t *testing.T) {
+    src := image.NewNRGBA(image.Rect(0, 0, 1000, 500))
+    for y := 0; y < 500; y++ {
+        for x := 0; x < 1000; x++ {
+            src.Set(x, y, color.NRGBA{255, 0, 0, 255})
+        }
+    }
+    dst := resizeImage(src, 240, 240)
+    if dst.Bounds().Dx() != 240 || dst.Bounds().Dy() != 120 {
+        t.Errorf("unexpected bounds: %v", dst.Bounds())
+    }
+    if r, g, b, _ := dst.At(100, 60).RGBA(); r != 0xffff || g != 0 || b != 0 {
+        t.Errorf("unexpected color: %v", dst.At(100, 60))
+    }
+
+    // transparent pixels become white
+    small := resizeImage(image.NewNRGBA(image.Rect(0, 0, 10, 20)), 240, 240)
+    if small.Bounds().Dx() != 10 || small.At(5, 5) != (color.RGBA{255, 255, 255, 255}) {
+        t.Errorf("unexpected image: %v %v", small.Bounds(), small.At(5, 5))
+    }
+
+    // opaque images that fit are not copied
+    gray := image.NewGray(image.Rect(0, 0, 10, 20))
+    if resizeImage(gray, 240, 240) != image.Image(gray) {
+        t.Error("opaque image was copied")
+    }
+}
+
+func Test_PrepareImage(t *testing.T) {
+    var buf bytes.Buffer
+    png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1500, 3000)))
+    prepared, err := PrepareImage(buf.Bytes())
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    tests := []struct {
+        data []byte
+        width int
+        height int
+    }{
+        {prepared.Original, 512, 1024},
+        {prepared.Preview, 120, 240},
+    }
+    for i, test := range tests {
+        config, err := jpeg.DecodeConfig(bytes.NewReader(test.data))
+        if err != nil {
+            t.Errorf("%d: %v", i, err)
+            continue
+        }
+        if config.Width != test.width || config.Height != test.height {
+            t.Errorf("%d: unexpected size: %dx%d", i, config.Width, config.Height)
+        }
+    }
+    if prepared.Width != 512 || prepared.Height != 1024 {
+        t.Errorf("unexpected size: %dx%d", prepared.Width, prepared.Height)
+    }
+
+    // small JPEGs are not re-encoded
+    buf.Reset()
+    jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 300, 200)), nil)
+    prepared, err = PrepareImage(buf.Bytes())
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if !bytes.Equal(prepared.Original, buf.Bytes()) {
+        t.Error("original was re-encoded")
+    }
+
+    _, err = PrepareImage([]byte("not an image"))
+    if err == nil {
+        t.Error("err is nil")
+    }
+}
+
+func Test_PrepareImage_TooLarge(t *testing.T) {
+    // only the header is needed to reject the image
+    var buf bytes.Buffer
+    png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+    data := buf.Bytes()
+    // IHDR width and height, and the chunk checksum
+    copy(data[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+    binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+    _, err := PrepareImage(data)
+    if err != ErrImageTooLarge {
+        t.Errorf("excepted: %v, actual: %v", ErrImageTooLarge, err)
+    }
+}
@@ -0,0 +1,215 @@
+package linebotapi
+
+import (
+    "sync"
+    "time"
+    "errors"
+    "context"
+    "container/list"
+)
+
+var ErrProfileNotFound = errors.New("linebotapi: profile not found")
+
+// ProfileCache caches the results of Client.GetUserProfiles by mid. Entries
+// expire after the configured TTL and the least recently used entry is
+// evicted once the cache holds more than size entries. Mids that are not
+// cached are fetched with a single /v1/profiles call, and concurrent lookups
+// of the same mid share one request.
+//
+// A shared request is not tied to the context of any one lookup: it runs
+// with FetchTimeout, and each lookup stops waiting when its own context is
+// done.
+type ProfileCache struct {
+    // FetchTimeout bounds each /v1/profiles call and defaults to 30 seconds.
+    FetchTimeout time.Duration
+
+    client *Client
+    size int
+    ttl time.Duration
+
+    mu sync.Mutex
+    lru *list.List
+    entries map[string]*list.Element
+    inflight map[string]*profileCall
+    stats ProfileCacheStats
+}
+
+type ProfileCacheStats struct {
+    Hits uint64
+    Misses uint64
+    // Shared counts lookups that waited for a request already in flight.
+    Shared uint64
+    Size int
+}
+
+type profileEntry struct {
+    contact Contact
+    expires time.Time
+}
+
+type profileCall struct {
+    done chan struct{}
+    contact *Contact
+    err error
+}
+
+// NewProfileCache returns a cache in front of client. A size of 0 or less
+// does not bound the number of entries.
+func NewProfileCache(client *Client, size int, ttl time.Duration) *ProfileCache {
+    return &ProfileCache{
+        client: client,
+        size: size,
+        ttl: ttl,
+        lru: list.New(),
+        entries: make(map[string]*list.Element),
+        inflight: make(map[string]*profileCall),
+    }
+}
+
+// Get returns the profile of mid, or ErrProfileNotFound when the API does not
+// return it.
+func (p *ProfileCache) Get(ctx context.Context, mid string) (*Contact, error) {
+    contacts, err := p.Lookup(ctx, []string{mid})
+    if err != nil {
+        return nil, err
+    }
+    if len(contacts.Contacts) == 0 {
+        return nil, ErrProfileNotFound
+    }
+    return &contacts.Contacts[0], nil
+}
+
+// Lookup returns the profiles of mids in the order given. Mids the API does
+// not know are left out of the result.
+func (p *ProfileCache) Lookup(ctx context.Context, mids []string) (*Contacts, error) {
+    found := make(map[string]*Contact, len(mids))
+    waiting := make(map[string]*profileCall)
+    fetching := make(map[string]*profileCall)
+    var fetch []string
+
+    p.mu.Lock()
+    now := time.Now()
+    for _, mid := range mids {
+        if _, exists := found[mid]; exists {
+            continue
+        }
+        if _, exists := waiting[mid]; exists {
+            continue
+        }
+        if contact, ok := p.cached(mid, now); ok {
+            p.stats.Hits++
+            found[mid] = contact
+        } else if call, exists := p.inflight[mid]; exists {
+            p.stats.Shared++
+            waiting[mid] = call
+        } else {
+            p.stats.Misses++
+            call := &profileCall{done: make(chan struct{})}
+            p.inflight[mid] = call
+            waiting[mid] = call
+            fetching[mid] = call
+            fetch = append(fetch, mid)
+        }
+    }
+    p.mu.Unlock()
+
+    if len(fetch) > 0 {
+        go p.fetch(fetch, fetching)
+    }
+
+    for mid, call := range waiting {
+        select {
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        case <-call.done:
+        }
+        if call.err != nil {
+            return nil, call.err
+        }
+        found[mid] = call.contact
+    }
+
+    result := &Contacts{}
+    seen := make(map[string]bool, len(mids))
+    for _, mid := range mids {
+        if contact := found[mid]; contact != nil && !seen[mid] {
+            seen[mid] = true
+            result.Contacts = append(result.Contacts, *contact)
+        }
+    }
+    result.Count = len(result.Contacts)
+    result.Total = len(result.Contacts)
+    result.Display = len(result.Contacts)
+    return result, nil
+}
+
+func (p *ProfileCache) Stats() ProfileCacheStats {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    stats := p.stats
+    stats.Size = p.lru.Len()
+    return stats
+}
+
+// cached must be called with p.mu held.
+func (p *ProfileCache) cached(mid string, now time.Time) (*Contact, bool) {
+    element, exists := p.entries[mid]
+    if !exists {
+        return nil, false
+    }
+    entry := element.Value.(*profileEntry)
+    if now.After(entry.expires) {
+        p.lru.Remove(element)
+        delete(p.entries, mid)
+        return nil, false
+    }
+    p.lru.MoveToFront(element)
+    contact := entry.contact
+    return &contact, true
+}
+
+func (p *ProfileCache) fetch(mids []string, calls map[string]*profileCall) {
+    timeout := p.FetchTimeout
+    if timeout <= 0 {
+        timeout = 30 * time.Second
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    contacts, err := p.client.GetUserProfilesContext(ctx, mids)
+
+    p.mu.Lock()
+    if err == nil {
+        expires := time.Now().Add(p.ttl)
+        for i := range contacts.Contacts {
+            contact := contacts.Contacts[i]
+            if call, exists := calls[contact.Mid]; exists {
+                call.contact = &contact
+                p.store(contact, expires)
+            }
+        }
+    }
+    for mid, call := range calls {
+        call.err = err
+        delete(p.inflight, mid)
+    }
+    p.mu.Unlock()
+
+    for _, call := range calls {
+        close(call.done)
+    }
+}
+
+// store must be called with p.mu held.
+func (p *ProfileCache) store(contact Contact, expires time.Time) {
+    if element, exists := p.entries[contact.Mid]; exists {
+        element.Value = &profileEntry{contact: contact, expires: expires}
+        p.lru.MoveToFront(element)
+        return
+    }
+    p.entries[contact.Mid] = p.lru.PushFront(&profileEntry{contact: contact, expires: expires})
+    for p.size > 0 && p.lru.Len() > p.size {
+        oldest := p.lru.Back()
+        p.lru.Remove(oldest)
+        delete(p.entries, oldest.Value.(*profileEntry).contact.Mid)
+    }
+}
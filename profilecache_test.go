@@ -0,0 +1,174 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "sync"
+    "time"
+    "context"
+    "strings"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+)
+
+func newProfileServer(requests *[]string, delay time.Duration) *httptest.Server {
+    var mu sync.Mutex
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        mids := r.URL.Query().Get("mids")
+        mu.Lock()
+        *requests = append(*requests, mids)
+        mu.Unlock()
+        time.Sleep(delay)
+        var contacts Contacts
+        for _, mid := range strings.Split(mids, ",") {
+            if mid == "unknown" {
+                continue
+            }
+            contacts.Contacts = append(contacts.Contacts, Contact{Mid: mid, DisplayName: "name-" + mid})
+        }
+        w.WriteHeader(200)
+        json.NewEncoder(w).Encode(contacts)
+    }))
+}
+
+func Test_ProfileCache_Lookup(t *testing.T) {
+    var requests []string
+    server := newProfileServer(&requests, 0)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    cache := NewProfileCache(client, 2, time.Minute)
+
+    contact, err := cache.Get(context.Background(), "a")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if contact.DisplayName != "name-a" {
+        t.Errorf("excepted: 'name-a', actual: '%s'", contact.DisplayName)
+    }
+    contacts, err := cache.Lookup(context.Background(), []string{"b", "a", "unknown", "b"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(contacts.Contacts) != 2 || contacts.Contacts[0].Mid != "b" || contacts.Contacts[1].Mid != "a" {
+        t.Errorf("unexpected contacts: %#v", contacts.Contacts)
+    }
+    if fmt.Sprint(requests) != "[a b,unknown]" {
+        t.Errorf("unexpected requests: %v", requests)
+    }
+    _, err = cache.Get(context.Background(), "unknown")
+    if err != ErrProfileNotFound {
+        t.Errorf("excepted: ErrProfileNotFound, actual: %v", err)
+    }
+
+    // "a" is used more recently than "b", so "b" is evicted by "c".
+    cache.Get(context.Background(), "a")
+    cache.Get(context.Background(), "c")
+    cache.Get(context.Background(), "a")
+    cache.Get(context.Background(), "b")
+    stats := cache.Stats()
+    if stats.Hits != 3 || stats.Misses != 6 || stats.Size != 2 {
+        t.Errorf("unexpected stats: %#v", stats)
+    }
+}
+
+func Test_ProfileCache_Expire(t *testing.T) {
+    var requests []string
+    server := newProfileServer(&requests, 0)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    cache := NewProfileCache(client, 0, 10 * time.Millisecond)
+
+    cache.Get(context.Background(), "a")
+    time.Sleep(20 * time.Millisecond)
+    cache.Get(context.Background(), "a")
+    if len(requests) != 2 {
+        t.Errorf("excepted: 2, actual: %d", len(requests))
+    }
+}
+
+func Test_ProfileCache_Singleflight(t *testing.T) {
+    var requests []string
+    server := newProfileServer(&requests, 50 * time.Millisecond)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    cache := NewProfileCache(client, 0, time.Minute)
+
+    var wg sync.WaitGroup
+    for i := 0; i < 10; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            contact, err := cache.Get(context.Background(), "a")
+            if err != nil || contact.Mid != "a" {
+                t.Errorf("unexpected result: %v %v", contact, err)
+            }
+        }()
+    }
+    wg.Wait()
+    if len(requests) != 1 {
+        t.Errorf("excepted: 1, actual: %d", len(requests))
+    }
+    if stats := cache.Stats(); stats.Misses + stats.Shared + stats.Hits != 10 {
+        t.Errorf("unexpected stats: %#v", stats)
+    }
+}
+
+func Test_ProfileCache_CanceledCaller(t *testing.T) {
+    var requests []string
+    server := newProfileServer(&requests, 50 * time.Millisecond)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    cache := NewProfileCache(client, 0, time.Minute)
+
+    // the caller starting the request gives up, the one sharing it does not
+    ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+    defer cancel()
+    errs := make(chan error, 1)
+    go func() {
+        _, err := cache.Get(ctx, "a")
+        errs <- err
+    }()
+    time.Sleep(5 * time.Millisecond)
+    contact, err := cache.Get(context.Background(), "a")
+    if err != nil || contact.Mid != "a" {
+        t.Errorf("unexpected result: %v %v", contact, err)
+    }
+    if err := <-errs; err != context.DeadlineExceeded {
+        t.Errorf("excepted: %v, actual: %v", context.DeadlineExceeded, err)
+    }
+    if len(requests) != 1 {
+        t.Errorf("excepted: 1, actual: %d", len(requests))
+    }
+}
@@ -0,0 +1,113 @@
+package linebotapi
+
+import (
+    "context"
+)
+
+// MaxProfileMids is the maximum number of mids sent in one /v1/profiles
+// request.
+const MaxProfileMids = 150
+
+func splitMids(mids []string) [][]string {
+    var chunks [][]string
+    for len(mids) > 0 {
+        n := MaxProfileMids
+        if n > len(mids) {
+            n = len(mids)
+        }
+        chunks = append(chunks, mids[:n:n])
+        mids = mids[n:]
+    }
+    return chunks
+}
+
+// nextPageStart returns the 1-based start of the page after page and reports
+// whether there is one. An empty page ends the paging.
+func nextPageStart(page *Contacts) (int, bool) {
+    if len(page.Contacts) == 0 || page.Display <= 0 {
+        return 0, false
+    }
+    next := page.Start + page.Display
+    return next, next <= page.Total
+}
+
+// ignoredStart reports whether the server answered a request for a later page
+// with some other page, which following would repeat forever.
+func ignoredStart(page *Contacts, start int) bool {
+    return start > 0 && page.Start != start
+}
+
+// ContactIterator walks the profiles of a mid list, splitting it into
+// API-sized requests and following the Start/Display paging of each
+// response until Total contacts have been read.
+//
+//     it := client.IterateUserProfiles(ctx, mids)
+//     for it.Next() {
+//         contact := it.Contact()
+//     }
+//     if err := it.Err(); err != nil {
+//     }
+type ContactIterator struct {
+    ctx context.Context
+    client *Client
+    chunks [][]string
+    page *Contacts
+    // start is the start requested for page
+    start int
+    index int
+    err error
+}
+
+func (c *Client) IterateUserProfiles(ctx context.Context, mids []string) *ContactIterator {
+    return &ContactIterator{
+        ctx: ctx,
+        client: c,
+        chunks: splitMids(mids),
+    }
+}
+
+// Next advances to the next contact and reports whether there is one.
+func (it *ContactIterator) Next() bool {
+    if it.err != nil {
+        return false
+    }
+    for it.page == nil || it.index + 1 >= len(it.page.Contacts) {
+        next, more := 0, false
+        if it.page != nil {
+            next, more = nextPageStart(it.page)
+        }
+        if more {
+            it.start = next
+        } else {
+            it.start = 0
+            if it.page != nil && len(it.chunks) > 0 {
+                it.chunks = it.chunks[1:]
+            }
+            if len(it.chunks) == 0 {
+                return false
+            }
+        }
+        it.page, it.err = it.client.getUserProfilesPage(it.ctx, it.chunks[0], it.start)
+        if it.err != nil {
+            return false
+        }
+        it.index = -1
+        if ignoredStart(it.page, it.start) {
+            it.page.Contacts = nil
+        }
+        if len(it.page.Contacts) > 0 {
+            break
+        }
+    }
+    it.index++
+    return true
+}
+
+// Contact returns the current contact.
+func (it *ContactIterator) Contact() *Contact {
+    return &it.page.Contacts[it.index]
+}
+
+func (it *ContactIterator) Err() error {
+    return it.err
+}
@@ -0,0 +1,200 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "context"
+    "strconv"
+    "strings"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+)
+
+// newPagingServer answers /v1/profiles with at most display contacts per
+// response, starting at the 1-based start parameter.
+func newPagingServer(display int, requests *[]string) *httptest.Server {
+    return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        *requests = append(*requests, r.URL.RawQuery)
+        mids := strings.Split(r.URL.Query().Get("mids"), ",")
+        start := 1
+        if s := r.URL.Query().Get("start"); s != "" {
+            start, _ = strconv.Atoi(s)
+        }
+        contacts := Contacts{Start: start, Total: len(mids), Contacts: []Contact{}}
+        for i := start - 1; i < len(mids) && len(contacts.Contacts) < display; i++ {
+            contacts.Contacts = append(contacts.Contacts, Contact{Mid: mids[i]})
+        }
+        contacts.Count = len(contacts.Contacts)
+        contacts.Display = len(contacts.Contacts)
+        w.WriteHeader(200)
+        json.NewEncoder(w).Encode(contacts)
+    }))
+}
+
+func Test_GetUserProfiles_Split(t *testing.T) {
+    var requests []string
+    server := newPagingServer(1000, &requests)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    mids := make([]string, 320)
+    for i := range mids {
+        mids[i] = fmt.Sprintf("u%d", i)
+    }
+    contacts, err := client.GetUserProfiles(mids)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(requests) != 3 {
+        t.Errorf("excepted: 3, actual: %d", len(requests))
+    }
+    if len(contacts.Contacts) != 320 || contacts.Count != 320 || contacts.Total != 320 || contacts.Start != 1 {
+        t.Errorf("unexpected contacts: count=%d total=%d start=%d len=%d", contacts.Count, contacts.Total, contacts.Start, len(contacts.Contacts))
+    }
+    if contacts.Contacts[319].Mid != "u319" {
+        t.Errorf("excepted: 'u319', actual: '%s'", contacts.Contacts[319].Mid)
+    }
+}
+
+func Test_IterateUserProfiles(t *testing.T) {
+    var requests []string
+    server := newPagingServer(100, &requests)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    mids := make([]string, 200)
+    for i := range mids {
+        mids[i] = fmt.Sprintf("u%d", i)
+    }
+    it := client.IterateUserProfiles(context.Background(), mids)
+    n := 0
+    for it.Next() {
+        if it.Contact().Mid != mids[n] {
+            t.Errorf("excepted: '%s', actual: '%s'", mids[n], it.Contact().Mid)
+            return
+        }
+        n++
+    }
+    if err := it.Err(); err != nil {
+        t.Error(err)
+        return
+    }
+    if n != 200 {
+        t.Errorf("excepted: 200, actual: %d", n)
+    }
+    // 150 mids in two pages, then 50 mids in one page
+    if len(requests) != 3 || !strings.HasSuffix(requests[1], "&start=101") {
+        t.Errorf("unexpected requests: %v", requests)
+    }
+    if it.Next() {
+        t.Error("excepted: false after the last contact")
+    }
+}
+
+func Test_GetUserProfiles_Paging(t *testing.T) {
+    var requests []string
+    server := newPagingServer(100, &requests)
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    mids := make([]string, 200)
+    for i := range mids {
+        mids[i] = fmt.Sprintf("u%d", i)
+    }
+    contacts, err := client.GetUserProfiles(mids)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    // 150 mids in two pages, then 50 mids in one page
+    if len(requests) != 3 || !strings.HasSuffix(requests[1], "&start=101") {
+        t.Errorf("unexpected requests: %v", requests)
+    }
+    if len(contacts.Contacts) != 200 || contacts.Total != 200 || contacts.Contacts[199].Mid != "u199" {
+        t.Errorf("unexpected contacts: total=%d len=%d", contacts.Total, len(contacts.Contacts))
+    }
+
+    // a server that ignores start returns the first page again, which ends
+    // the paging instead of repeating it
+    var ignored []string
+    ignoring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ignored = append(ignored, r.URL.RawQuery)
+        w.WriteHeader(200)
+        json.NewEncoder(w).Encode(Contacts{Start: 1, Display: 1, Total: 2, Contacts: []Contact{{Mid: "u0"}}})
+    }))
+    defer ignoring.Close()
+    client.BaseURL = ignoring.URL
+    contacts, err = client.GetUserProfiles([]string{"u0", "u1"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(ignored) != 2 || len(contacts.Contacts) != 1 {
+        t.Errorf("unexpected result: %v %d", ignored, len(contacts.Contacts))
+    }
+    ignored = nil
+    n := 0
+    it := client.IterateUserProfiles(context.Background(), []string{"u0", "u1"})
+    for it.Next() {
+        n++
+    }
+    if len(ignored) != 2 || n != 1 || it.Err() != nil {
+        t.Errorf("unexpected result: %v %d %v", ignored, n, it.Err())
+    }
+}
+
+func Test_IterateUserProfiles_Start(t *testing.T) {
+    // a page can hold fewer contacts than it displays, e.g. for unknown mids
+    var requests []string
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests = append(requests, r.URL.RawQuery)
+        contacts := Contacts{Start: 1, Display: 2, Total: 3, Contacts: []Contact{{Mid: "u1"}}}
+        if r.URL.Query().Get("start") == "3" {
+            contacts = Contacts{Start: 3, Display: 1, Total: 3, Contacts: []Contact{{Mid: "u3"}}}
+        }
+        w.WriteHeader(200)
+        json.NewEncoder(w).Encode(contacts)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    it := client.IterateUserProfiles(context.Background(), []string{"u1", "u2", "u3"})
+    var mids []string
+    for it.Next() {
+        mids = append(mids, it.Contact().Mid)
+    }
+    if err := it.Err(); err != nil {
+        t.Error(err)
+        return
+    }
+    if strings.Join(mids, ",") != "u1,u3" || len(requests) != 2 {
+        t.Errorf("unexpected result: %v %v", mids, requests)
+    }
+}
@@ -0,0 +1,154 @@
+package linebotapi
+
+import (
+    "sync"
+    "time"
+    "context"
+)
+
+const (
+    EndpointEvents = "/v1/events"
+    EndpointProfiles = "/v1/profiles"
+)
+
+// RateLimit configures a token bucket that allows Rate requests per second
+// with bursts of up to Burst requests.
+type RateLimit struct {
+    Rate float64
+    Burst int
+}
+
+// RateLimiter throttles outbound API calls per endpoint path, e.g.
+// EndpointEvents. Endpoints without a configured limit are not throttled.
+//
+// When the server answers with a rate-limit error, the endpoint is paused for
+// the Retry-After delay (or one token interval) and its rate is halved. Each
+// successful call then restores a tenth of the configured rate.
+type RateLimiter struct {
+    mu sync.Mutex
+    buckets map[string]*tokenBucket
+}
+
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+    l := &RateLimiter{
+        buckets: make(map[string]*tokenBucket),
+    }
+    now := time.Now()
+    for endpoint, limit := range limits {
+        if limit.Rate <= 0 {
+            continue
+        }
+        if limit.Burst < 1 {
+            limit.Burst = 1
+        }
+        l.buckets[endpoint] = &tokenBucket{
+            limit: limit,
+            rate: limit.Rate,
+            tokens: float64(limit.Burst),
+            last: now,
+        }
+    }
+    return l
+}
+
+// Wait blocks until a request to endpoint is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, endpoint string) error {
+    if l == nil {
+        return nil
+    }
+    l.mu.Lock()
+    b, exists := l.buckets[endpoint]
+    if !exists {
+        l.mu.Unlock()
+        return nil
+    }
+    wait := b.reserve(time.Now())
+    l.mu.Unlock()
+    if wait <= 0 {
+        return nil
+    }
+    err := sleepContext(ctx, wait)
+    if err != nil {
+        l.mu.Lock()
+        b.tokens++
+        l.mu.Unlock()
+    }
+    return err
+}
+
+// observe adapts the endpoint's rate to the outcome of a call.
+func (l *RateLimiter) observe(endpoint string, err error) {
+    if l == nil {
+        return
+    }
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    b, exists := l.buckets[endpoint]
+    if !exists {
+        return
+    }
+    if err == nil {
+        b.recover()
+    } else if IsRateLimited(err) {
+        e, _ := asAPIError(err)
+        b.penalize(time.Now(), e.RetryAfter)
+    }
+}
+
+type tokenBucket struct {
+    limit RateLimit
+    rate float64
+    tokens float64
+    last time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+    if !now.After(b.last) {
+        return
+    }
+    b.tokens += b.rate * now.Sub(b.last).Seconds()
+    if b.tokens > float64(b.limit.Burst) {
+        b.tokens = float64(b.limit.Burst)
+    }
+    b.last = now
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+    b.refill(now)
+    b.tokens--
+    var wait time.Duration
+    if b.last.After(now) {
+        wait = b.last.Sub(now)
+    }
+    if b.tokens < 0 {
+        wait += time.Duration(-b.tokens / b.rate * float64(time.Second))
+    }
+    return wait
+}
+
+func (b *tokenBucket) penalize(now time.Time, retryAfter time.Duration) {
+    b.rate /= 2
+    if min := b.limit.Rate / 16; b.rate < min {
+        b.rate = min
+    }
+    pause := time.Duration(float64(time.Second) / b.rate)
+    if retryAfter > pause {
+        pause = retryAfter
+    }
+    b.refill(now)
+    if until := now.Add(pause); until.After(b.last) {
+        b.last = until
+    }
+    if b.tokens > 1 {
+        b.tokens = 1
+    }
+}
+
+func (b *tokenBucket) recover() {
+    b.rate += b.limit.Rate / 10
+    if b.rate > b.limit.Rate {
+        b.rate = b.limit.Rate
+    }
+}
@@ -0,0 +1,99 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "time"
+    "errors"
+    "context"
+    "net/http"
+    "net/http/httptest"
+)
+
+func Test_RateLimiter_Throttle(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.WriteHeader(200)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.RateLimiter = NewRateLimiter(map[string]RateLimit{
+        EndpointEvents: {Rate: 20, Burst: 1},
+    })
+    start := time.Now()
+    for i := 0; i < 4; i++ {
+        err := client.SendText([]string{"test"}, "message")
+        if err != nil {
+            t.Error(err)
+            return
+        }
+    }
+    if elapsed := time.Since(start); elapsed < 140 * time.Millisecond {
+        t.Errorf("excepted: >= 150ms, actual: %s", elapsed)
+    }
+}
+
+func Test_RateLimiter_WaitCanceled(t *testing.T) {
+    limiter := NewRateLimiter(map[string]RateLimit{
+        EndpointProfiles: {Rate: 0.1, Burst: 1},
+    })
+    ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+    defer cancel()
+    if err := limiter.Wait(ctx, EndpointProfiles); err != nil {
+        t.Error(err)
+        return
+    }
+    err := limiter.Wait(ctx, EndpointProfiles)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("excepted: context.DeadlineExceeded, actual: %v", err)
+    }
+    if err := limiter.Wait(ctx, EndpointEvents); err != nil {
+        t.Errorf("unlimited endpoint: %v", err)
+    }
+}
+
+func Test_RateLimiter_Adaptive(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Header().Set("Retry-After", "1")
+        w.WriteHeader(429)
+        fmt.Fprintf(w, `{"statusCode":"429","statusMessage":"rate limit exceeded"}`)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.RateLimiter = NewRateLimiter(map[string]RateLimit{
+        EndpointEvents: {Rate: 100, Burst: 10},
+    })
+    err := client.SendText([]string{"test"}, "message")
+    if !IsRateLimited(err) {
+        t.Errorf("excepted: rate limit error, actual: %v", err)
+        return
+    }
+    b := client.RateLimiter.buckets[EndpointEvents]
+    if b.rate != 50 {
+        t.Errorf("excepted: 50, actual: %v", b.rate)
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 100 * time.Millisecond)
+    defer cancel()
+    err = client.RateLimiter.Wait(ctx, EndpointEvents)
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("excepted: paused until Retry-After, actual: %v", err)
+    }
+    client.RateLimiter.observe(EndpointEvents, nil)
+    if b.rate != 60 {
+        t.Errorf("excepted: 60, actual: %v", b.rate)
+    }
+}
@@ -0,0 +1,122 @@
+package linebotapi
+
+import (
+    "io"
+    "net"
+    "time"
+    "errors"
+    "context"
+    "math/rand"
+    "net/url"
+    "net/http"
+)
+
+// RetryPolicy controls how Client retries API calls that fail with a
+// network error, a 5xx status or a 429 status. The delay before attempt n+1
+// is InitialBackoff * Multiplier^(n-1), capped at MaxBackoff, reduced by a
+// random fraction of up to Jitter, and never shorter than the server's
+// Retry-After. A Retry-After longer than MaxBackoff stops the retries.
+//
+// Retries also apply to SendMessage and SendMessages, so a request that
+// reached the server before the connection failed may be delivered twice.
+type RetryPolicy struct {
+    // MaxAttempts is the total number of attempts, including the first one.
+    MaxAttempts int
+    // InitialBackoff defaults to 500ms.
+    InitialBackoff time.Duration
+    // MaxBackoff defaults to 30s.
+    MaxBackoff time.Duration
+    // Multiplier defaults to 2.
+    Multiplier float64
+    // Jitter is a fraction between 0 and 1.
+    Jitter float64
+    // OnAttempt is called after every attempt.
+    OnAttempt func(a RetryAttempt)
+}
+
+// RetryAttempt describes the outcome of one attempt. Wait is the delay before
+// the next attempt and is zero when no further attempt will be made.
+type RetryAttempt struct {
+    Attempt int
+    Method string
+    Path string
+    Err error
+    Wait time.Duration
+}
+
+func (p *RetryPolicy) report(attempt int, req *http.Request, err error, wait time.Duration) {
+    if p == nil || p.OnAttempt == nil {
+        return
+    }
+    p.OnAttempt(RetryAttempt{
+        Attempt: attempt,
+        Method: req.Method,
+        Path: req.URL.Path,
+        Err: err,
+        Wait: wait,
+    })
+}
+
+// backoff returns the delay before the next attempt and whether err is worth
+// retrying at all.
+func (p *RetryPolicy) backoff(attempt int, err error) (time.Duration, bool) {
+    if p == nil || attempt >= p.MaxAttempts || !isRetryable(err) {
+        return 0, false
+    }
+    initial, max, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+    if initial <= 0 {
+        initial = 500 * time.Millisecond
+    }
+    if max <= 0 {
+        max = 30 * time.Second
+    }
+    if multiplier < 1 {
+        multiplier = 2
+    }
+    wait := float64(initial)
+    for i := 1; i < attempt && wait < float64(max); i++ {
+        wait *= multiplier
+    }
+    if wait > float64(max) {
+        wait = float64(max)
+    }
+    if p.Jitter > 0 {
+        wait -= wait * p.Jitter * rand.Float64()
+    }
+    d := time.Duration(wait)
+    if e, ok := asAPIError(err); ok && e.RetryAfter > d {
+        if e.RetryAfter > max {
+            return 0, false
+        }
+        d = e.RetryAfter
+    }
+    return d, true
+}
+
+func isRetryable(err error) bool {
+    if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+        return false
+    }
+    if e, ok := asAPIError(err); ok {
+        return e.HTTPStatus >= 500 || e.hasStatus(http.StatusTooManyRequests)
+    }
+    // *url.Error is itself a net.Error, so look at what it wraps; a bad URL
+    // or an unsupported scheme fails the same way every time
+    var urlErr *url.Error
+    if errors.As(err, &urlErr) {
+        err = urlErr.Err
+    }
+    var netErr net.Error
+    return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+    timer := time.NewTimer(d)
+    defer timer.Stop()
+    select {
+    case <-ctx.Done():
+        return ctx.Err()
+    case <-timer.C:
+        return nil
+    }
+}
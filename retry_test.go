@@ -0,0 +1,164 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "io"
+    "fmt"
+    "net"
+    "time"
+    "errors"
+    "context"
+    "net/url"
+    "net/http"
+    "net/http/httptest"
+)
+
+func Test_Retry_SendMessage(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        if requests <= 2 {
+            w.WriteHeader(503)
+            fmt.Fprintf(w, `{"statusCode":"503","statusMessage":"unavailable"}`)
+            return
+        }
+        w.WriteHeader(200)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    var attempts []RetryAttempt
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.Retry = &RetryPolicy{
+        MaxAttempts: 3,
+        InitialBackoff: time.Millisecond,
+        Jitter: 0.5,
+        OnAttempt: func(a RetryAttempt) {
+            attempts = append(attempts, a)
+        },
+    }
+    err := client.SendMessage([]string{"test"}, NewMessageText("message"))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if requests != 3 {
+        t.Errorf("excepted: 3, actual: %d", requests)
+    }
+    if len(attempts) != 3 || attempts[0].Err == nil || attempts[0].Wait == 0 || attempts[2].Err != nil || attempts[2].Path != "/v1/events" {
+        t.Errorf("unexpected attempts: %#v", attempts)
+    }
+}
+
+func Test_Retry_GiveUp(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.WriteHeader(500)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.Retry = &RetryPolicy{
+        MaxAttempts: 2,
+        InitialBackoff: time.Millisecond,
+    }
+    _, err := client.GetUserProfiles([]string{"u0047556f2e40dba2456887320ba7c76d"})
+    if err == nil {
+        t.Error("err is nil")
+    }
+    if requests != 2 {
+        t.Errorf("excepted: 2, actual: %d", requests)
+    }
+}
+
+func Test_Retry_ClientError(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.WriteHeader(400)
+        fmt.Fprintf(w, `{"statusCode":"400","statusMessage":"error"}`)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.Retry = &RetryPolicy{
+        MaxAttempts: 5,
+        InitialBackoff: time.Millisecond,
+    }
+    err := client.SendText([]string{"test"}, "message")
+    if err == nil {
+        t.Error("err is nil")
+    }
+    if requests != 1 {
+        t.Errorf("excepted: 1, actual: %d", requests)
+    }
+}
+
+func Test_RetryPolicy_RetryAfter(t *testing.T) {
+    policy := &RetryPolicy{
+        MaxAttempts: 3,
+        InitialBackoff: time.Millisecond,
+    }
+    wait, ok := policy.backoff(1, &APIError{HTTPStatus: 429, RetryAfter: 2 * time.Second})
+    if !ok || wait != 2 * time.Second {
+        t.Errorf("excepted: 2s, actual: %s", wait)
+    }
+    wait, ok = policy.backoff(2, &APIError{HTTPStatus: 502})
+    if !ok || wait != 2 * time.Millisecond {
+        t.Errorf("excepted: 2ms, actual: %s", wait)
+    }
+    _, ok = policy.backoff(3, &APIError{HTTPStatus: 502})
+    if ok {
+        t.Error("excepted: no retry after MaxAttempts")
+    }
+}
+
+func Test_RetryPolicy_RetryAfterTooLong(t *testing.T) {
+    policy := &RetryPolicy{
+        MaxAttempts: 3,
+        MaxBackoff: time.Second,
+    }
+    _, ok := policy.backoff(1, &APIError{HTTPStatus: 429, RetryAfter: time.Minute})
+    if ok {
+        t.Error("excepted: no retry when Retry-After exceeds MaxBackoff")
+    }
+}
+
+func Test_IsRetryable(t *testing.T) {
+    tests := []struct {
+        err error
+        retryable bool
+    }{
+        {&url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}, true},
+        {&url.Error{Op: "Post", URL: "https://example.com", Err: io.EOF}, true},
+        {&url.Error{Op: "Post", URL: "ftp://example.com", Err: errors.New("unsupported protocol scheme \"ftp\"")}, false},
+        {&url.Error{Op: "parse", URL: ":", Err: errors.New("missing protocol scheme")}, false},
+        {&url.Error{Op: "Post", URL: "https://example.com", Err: context.Canceled}, false},
+        {&APIError{HTTPStatus: 503}, true},
+        {&APIError{HTTPStatus: 400}, false},
+    }
+    for i, test := range tests {
+        if isRetryable(test.err) != test.retryable {
+            t.Errorf("%d: excepted: %v, actual: %v", i, test.retryable, !test.retryable)
+        }
+    }
+}
@@ -0,0 +1,288 @@
+package linebotapi
+
+import (
+    "fmt"
+    "sort"
+    "net/url"
+    "encoding/json"
+)
+
+const (
+    RichCanvasWidth = 1040
+    MaxRichCanvasHeight = 2080
+)
+
+const (
+    RichActionWeb = "web"
+    RichActionSendMessage = "sendMessage"
+)
+
+// MessageRich is a rich message: a base image with tappable areas. The image
+// is fetched from DownloadURL with the width in pixels appended to the path,
+// e.g. DownloadURL/1040 and DownloadURL/240.
+type MessageRich struct {
+    DownloadURL string
+    AltText string
+    Markup *RichMarkup
+}
+func (c *MessageRich) Map() map[string]interface{} {
+    markup, _ := json.Marshal(c.Markup)
+    return map[string]interface{}{
+        "contentType": ContentTypeRich,
+        "toType": ToTypeUser,
+        "contentMetadata": map[string]string{
+            "DOWNLOAD_URL": c.DownloadURL,
+            "SPEC_REV": "1",
+            "ALT_TEXT": c.AltText,
+            "MARKUP_JSON": string(markup),
+        },
+    }
+}
+
+// Validate checks that DownloadURL is an HTTPS URL and the markup is
+// consistent.
+func (c *MessageRich) Validate() error {
+    if err := checkURL("contentMetadata.DOWNLOAD_URL", c.DownloadURL); err != nil {
+        return err
+    }
+    if err := checkText("contentMetadata.ALT_TEXT", c.AltText); err != nil {
+        return err
+    }
+    if c.Markup == nil {
+        return &FieldError{Field: "contentMetadata.MARKUP_JSON"}
+    }
+    return c.Markup.Validate()
+}
+
+func NewMessageRich(downloadURL, altText string, markup *RichMarkup) *MessageContent {
+    return &MessageContent{
+        ContentType: ContentTypeRich,
+        Content: &MessageRich{
+            DownloadURL: downloadURL,
+            AltText: altText,
+            Markup: markup,
+        },
+    }
+}
+
+// RichMarkup is the MARKUP_JSON of a rich message. NewRichMarkup and its Add
+// methods cover the usual single scene layout; the fields can be filled in
+// directly for anything else.
+type RichMarkup struct {
+    Canvas RichCanvas `json:"canvas"`
+    Images map[string]RichImage `json:"images"`
+    Actions map[string]RichAction `json:"actions"`
+    Scenes map[string]*RichScene `json:"scenes"`
+}
+
+type RichCanvas struct {
+    Width int `json:"width"`
+    Height int `json:"height"`
+    InitialScene string `json:"initialScene"`
+}
+
+// RichImage is a region of the base image.
+type RichImage struct {
+    X int `json:"x"`
+    Y int `json:"y"`
+    W int `json:"w"`
+    H int `json:"h"`
+}
+
+// RichAction takes "linkUri" in Params for RichActionWeb and "text" for
+// RichActionSendMessage. Text is shown to the user.
+type RichAction struct {
+    Type string `json:"type"`
+    Text string `json:"text"`
+    Params map[string]string `json:"params"`
+}
+
+type RichScene struct {
+    Draws []RichDraw `json:"draws"`
+    Listeners []RichListener `json:"listeners"`
+}
+
+// RichDraw draws an image on the canvas.
+type RichDraw struct {
+    Image string `json:"image"`
+    X int `json:"x"`
+    Y int `json:"y"`
+    W int `json:"w"`
+    H int `json:"h"`
+}
+
+// RichListener runs Action when the area given by Params as x, y, width and
+// height is touched.
+type RichListener struct {
+    Type string `json:"type"`
+    Params [4]int `json:"params"`
+    Action string `json:"action"`
+}
+
+// NewRichMarkup returns markup for a base image RichCanvasWidth wide and
+// height high, drawn whole in a single scene.
+func NewRichMarkup(height int) *RichMarkup {
+    return &RichMarkup{
+        Canvas: RichCanvas{
+            Width: RichCanvasWidth,
+            Height: height,
+            InitialScene: "scene1",
+        },
+        Images: map[string]RichImage{
+            "image1": {X: 0, Y: 0, W: RichCanvasWidth, H: height},
+        },
+        Actions: map[string]RichAction{},
+        Scenes: map[string]*RichScene{
+            "scene1": {
+                Draws: []RichDraw{
+                    {Image: "image1", X: 0, Y: 0, W: RichCanvasWidth, H: height},
+                },
+                Listeners: []RichListener{},
+            },
+        },
+    }
+}
+
+// AddWebAction opens linkURI when the area is touched.
+func (m *RichMarkup) AddWebAction(x, y, w, h int, text, linkURI string) *RichMarkup {
+    return m.addAction(x, y, w, h, RichAction{
+        Type: RichActionWeb,
+        Text: text,
+        Params: map[string]string{"linkUri": linkURI},
+    })
+}
+
+// AddMessageAction sends message as the user when the area is touched.
+func (m *RichMarkup) AddMessageAction(x, y, w, h int, text, message string) *RichMarkup {
+    return m.addAction(x, y, w, h, RichAction{
+        Type: RichActionSendMessage,
+        Text: text,
+        Params: map[string]string{"text": message},
+    })
+}
+
+// addAction adds the action with a listener in the initial scene.
+func (m *RichMarkup) addAction(x, y, w, h int, action RichAction) *RichMarkup {
+    if m.Actions == nil {
+        m.Actions = map[string]RichAction{}
+    }
+    if m.Scenes == nil {
+        m.Scenes = map[string]*RichScene{}
+    }
+    // actions can be added or removed directly, so skip names in use
+    var name string
+    for i := len(m.Actions) + 1; ; i++ {
+        name = fmt.Sprintf("action%d", i)
+        if _, exists := m.Actions[name]; !exists {
+            break
+        }
+    }
+    m.Actions[name] = action
+    scene := m.Scenes[m.Canvas.InitialScene]
+    if scene == nil {
+        scene = &RichScene{}
+        m.Scenes[m.Canvas.InitialScene] = scene
+    }
+    scene.Listeners = append(scene.Listeners, RichListener{
+        Type: "touch",
+        Params: [4]int{x, y, w, h},
+        Action: name,
+    })
+    return m
+}
+
+// Validate checks the canvas size, that every image, draw and listener area
+// lies within the canvas and that every name refers to something defined.
+// The first problem is returned as a *FieldError.
+func (m *RichMarkup) Validate() error {
+    if m.Canvas.Width != RichCanvasWidth {
+        return &FieldError{Field: "canvas.width", Expected: fmt.Sprint(RichCanvasWidth), Actual: fmt.Sprint(m.Canvas.Width)}
+    }
+    if m.Canvas.Height <= 0 || m.Canvas.Height > MaxRichCanvasHeight {
+        return &FieldError{Field: "canvas.height", Expected: fmt.Sprintf("1 to %d", MaxRichCanvasHeight), Actual: fmt.Sprint(m.Canvas.Height)}
+    }
+    if _, exists := m.Scenes[m.Canvas.InitialScene]; !exists {
+        return &FieldError{Field: "canvas.initialScene", Expected: "a defined scene", Actual: fmt.Sprintf("'%s'", m.Canvas.InitialScene)}
+    }
+
+    for _, name := range sortedKeys(m.Images) {
+        image := m.Images[name]
+        if err := m.checkArea("images." + name, image.X, image.Y, image.W, image.H); err != nil {
+            return err
+        }
+    }
+    for _, name := range sortedKeys(m.Actions) {
+        action := m.Actions[name]
+        field := "actions." + name
+        switch action.Type {
+        case RichActionWeb:
+            u, err := url.Parse(action.Params["linkUri"])
+            if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+                return &FieldError{Field: field + ".params.linkUri", Expected: "http or https URL", Actual: fmt.Sprintf("'%s'", action.Params["linkUri"])}
+            }
+        case RichActionSendMessage:
+            if action.Params["text"] == "" {
+                return &FieldError{Field: field + ".params.text"}
+            }
+        default:
+            return &FieldError{Field: field + ".type", Expected: "web or sendMessage", Actual: fmt.Sprintf("'%s'", action.Type)}
+        }
+    }
+    for _, name := range sortedKeys(m.Scenes) {
+        scene := m.Scenes[name]
+        for i, draw := range scene.Draws {
+            field := fmt.Sprintf("scenes.%s.draws[%d]", name, i)
+            if _, exists := m.Images[draw.Image]; !exists {
+                return &FieldError{Field: field + ".image", Expected: "a defined image", Actual: fmt.Sprintf("'%s'", draw.Image)}
+            }
+            if err := m.checkArea(field, draw.X, draw.Y, draw.W, draw.H); err != nil {
+                return err
+            }
+        }
+        for i, listener := range scene.Listeners {
+            field := fmt.Sprintf("scenes.%s.listeners[%d]", name, i)
+            if listener.Type != "touch" {
+                return &FieldError{Field: field + ".type", Expected: "touch", Actual: fmt.Sprintf("'%s'", listener.Type)}
+            }
+            if _, exists := m.Actions[listener.Action]; !exists {
+                return &FieldError{Field: field + ".action", Expected: "a defined action", Actual: fmt.Sprintf("'%s'", listener.Action)}
+            }
+            p := listener.Params
+            if err := m.checkArea(field + ".params", p[0], p[1], p[2], p[3]); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+func (m *RichMarkup) checkArea(field string, x, y, w, h int) error {
+    if x < 0 || y < 0 || w <= 0 || h <= 0 || x + w > m.Canvas.Width || y + h > m.Canvas.Height {
+        return &FieldError{
+            Field: field,
+            Expected: fmt.Sprintf("area within %dx%d canvas", m.Canvas.Width, m.Canvas.Height),
+            Actual: fmt.Sprintf("x=%d y=%d w=%d h=%d", x, y, w, h),
+        }
+    }
+    return nil
+}
+
+func sortedKeys(m interface{}) []string {
+    var keys []string
+    switch m := m.(type) {
+    case map[string]RichImage:
+        for key := range m {
+            keys = append(keys, key)
+        }
+    case map[string]RichAction:
+        for key := range m {
+            keys = append(keys, key)
+        }
+    case map[string]*RichScene:
+        for key := range m {
+            keys = append(keys, key)
+        }
+    }
+    sort.Strings(keys)
+    return keys
+}
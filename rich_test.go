@@ -0,0 +1,84 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "encoding/json"
+)
+
+func Test_NewMessageRich(t *testing.T) {
+    markup := NewRichMarkup(1040).
+        AddWebAction(0, 0, 1040, 520, "Open our homepage.", "https://example.com/").
+        AddMessageAction(0, 520, 1040, 520, "Show item", "show item")
+    content := NewMessageRich("https://example.com/rich/1", "Rich message", markup)
+    if content.ContentType != ContentTypeRich {
+        t.Errorf("excepted: %d, actual: %d", ContentTypeRich, content.ContentType)
+    }
+    err := content.Content.(*MessageRich).Validate()
+    if err != nil {
+        t.Error(err)
+        return
+    }
+
+    metadata := content.Content.Map()["contentMetadata"].(map[string]string)
+    if metadata["SPEC_REV"] != "1" || metadata["DOWNLOAD_URL"] != "https://example.com/rich/1" || metadata["ALT_TEXT"] != "Rich message" {
+        t.Errorf("unexpected metadata: %#v", metadata)
+    }
+    var decoded RichMarkup
+    err = json.Unmarshal([]byte(metadata["MARKUP_JSON"]), &decoded)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    listeners := decoded.Scenes["scene1"].Listeners
+    if len(listeners) != 2 || listeners[1].Action != "action2" || listeners[1].Params != [4]int{0, 520, 1040, 520} {
+        t.Errorf("unexpected listeners: %#v", listeners)
+    }
+    if decoded.Actions["action1"].Params["linkUri"] != "https://example.com/" {
+        t.Errorf("unexpected actions: %#v", decoded.Actions)
+    }
+}
+
+func Test_RichMarkup_Validate(t *testing.T) {
+    tests := []struct {
+        markup *RichMarkup
+        field string
+    }{
+        {NewRichMarkup(3000), "canvas.height"},
+        {NewRichMarkup(1040).AddWebAction(0, 600, 1040, 520, "text", "https://example.com/"), "scenes.scene1.listeners[0].params"},
+        {NewRichMarkup(1040).AddWebAction(-1, 0, 100, 100, "text", "https://example.com/"), "scenes.scene1.listeners[0].params"},
+        {NewRichMarkup(1040).AddWebAction(0, 0, 100, 100, "text", "javascript:alert(1)"), "actions.action1.params.linkUri"},
+        {NewRichMarkup(1040).AddMessageAction(0, 0, 100, 100, "text", ""), "actions.action1.params.text"},
+    }
+    for i, test := range tests {
+        err := test.markup.Validate()
+        fieldErr, ok := err.(*FieldError)
+        if !ok || fieldErr.Field != test.field {
+            t.Errorf("%d: excepted: %s, actual: %v", i, test.field, err)
+        }
+    }
+
+    markup := NewRichMarkup(1040)
+    markup.Scenes["scene1"].Draws[0].Image = "missing"
+    if err := markup.Validate(); err == nil {
+        t.Error("err is nil")
+    }
+    if err := (&MessageRich{DownloadURL: "https://example.com/rich/1", AltText: "alt"}).Validate(); err == nil {
+        t.Error("err is nil")
+    }
+}
+
+func Test_RichMarkup_ActionNames(t *testing.T) {
+    markup := NewRichMarkup(1040).
+        AddWebAction(0, 0, 1040, 520, "one", "https://example.com/1").
+        AddWebAction(0, 520, 1040, 520, "two", "https://example.com/2")
+    delete(markup.Actions, "action1")
+    markup.AddMessageAction(0, 0, 520, 520, "three", "three")
+    if len(markup.Actions) != 2 || markup.Actions["action2"].Text != "two" {
+        t.Errorf("action was overwritten: %#v", markup.Actions)
+    }
+    listeners := markup.Scenes["scene1"].Listeners
+    if name := listeners[len(listeners) - 1].Action; markup.Actions[name].Text != "three" {
+        t.Errorf("unexpected action: '%s'", name)
+    }
+}
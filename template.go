@@ -0,0 +1,127 @@
+package linebotapi
+
+import (
+    "bytes"
+    "context"
+    "text/template"
+)
+
+// TextTemplate renders a text message for each recipient from their
+// Contact. The Contact is dot, and the functions displayName, mid,
+// pictureUrl and statusMessage return its fields, so both of these work:
+//
+//     Hello, {{.DisplayName}}
+//     Hello, {{displayName}}
+type TextTemplate struct {
+    tmpl *template.Template
+}
+
+// ParseTextTemplate parses text with text/template.
+func ParseTextTemplate(text string) (*TextTemplate, error) {
+    tmpl, err := template.New("text").Funcs(contactFuncs(&Contact{})).Parse(text)
+    if err != nil {
+        return nil, err
+    }
+    return &TextTemplate{tmpl: tmpl}, nil
+}
+
+func contactFuncs(contact *Contact) template.FuncMap {
+    return template.FuncMap{
+        "displayName": func() string { return contact.DisplayName },
+        "mid": func() string { return contact.Mid },
+        "pictureUrl": func() string { return contact.PictureUrl },
+        "statusMessage": func() string { return contact.StatusMessage },
+    }
+}
+
+// Render returns the text message for contact. It is safe for concurrent
+// use.
+func (t *TextTemplate) Render(contact *Contact) (*MessageContent, error) {
+    tmpl, err := t.tmpl.Clone()
+    if err != nil {
+        return nil, err
+    }
+    var buf bytes.Buffer
+    err = tmpl.Funcs(contactFuncs(contact)).Execute(&buf, contact)
+    if err != nil {
+        return nil, err
+    }
+    return NewMessageText(buf.String()), nil
+}
+
+// BroadcastTemplate sends every mid in to the text t renders from its
+// profile. Profiles are fetched through profiles when it is not nil, and
+// with GetUserProfiles otherwise. Recipients whose texts are identical share
+// events, which are chunked and sent like in Broadcast. Mids without a
+// profile are reported as a failed chunk with ErrProfileNotFound.
+//
+// An error is returned, and nothing is sent, when the profiles cannot be
+// fetched or the template fails to render.
+func (c *Client) BroadcastTemplate(ctx context.Context, to []string, t *TextTemplate, profiles *ProfileCache, opts *BroadcastOptions) (*BroadcastReport, error) {
+    var contacts *Contacts
+    var err error
+    if profiles != nil {
+        contacts, err = profiles.Lookup(ctx, to)
+    } else {
+        contacts, err = c.GetUserProfilesContext(ctx, to)
+    }
+    if err != nil {
+        return nil, err
+    }
+    byMid := make(map[string]*Contact, len(contacts.Contacts))
+    for i := range contacts.Contacts {
+        byMid[contacts.Contacts[i].Mid] = &contacts.Contacts[i]
+    }
+
+    // group recipients by rendered text, in the order of to
+    var texts []string
+    groups := make(map[string][]string)
+    rendered := make(map[string]*MessageContent)
+    var missing []string
+    seen := make(map[string]bool, len(to))
+    for _, mid := range to {
+        if seen[mid] {
+            continue
+        }
+        seen[mid] = true
+        contact, exists := byMid[mid]
+        if !exists {
+            missing = append(missing, mid)
+            continue
+        }
+        content, err := t.Render(contact)
+        if err != nil {
+            return nil, err
+        }
+        text := content.Content.(*MessageText).Text
+        if _, exists := groups[text]; !exists {
+            texts = append(texts, text)
+            rendered[text] = content
+        }
+        groups[text] = append(groups[text], mid)
+    }
+
+    var chunkSize int
+    if opts != nil {
+        chunkSize = opts.ChunkSize
+    }
+    var chunks [][]string
+    var contents []*MessageContent
+    for _, text := range texts {
+        for _, chunk := range splitRecipients(groups[text], chunkSize) {
+            chunks = append(chunks, chunk)
+            contents = append(contents, rendered[text])
+        }
+    }
+    report := sendChunks(ctx, chunks, opts, func(ctx context.Context, index int, chunk []string) error {
+        return c.SendMessageContext(ctx, chunk, contents[index])
+    })
+    if len(missing) > 0 {
+        report.Chunks = append(report.Chunks, ChunkResult{
+            Index: len(report.Chunks),
+            To: missing,
+            Err: ErrProfileNotFound,
+        })
+    }
+    return report, nil
+}
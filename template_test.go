@@ -0,0 +1,73 @@
+package linebotapi_test
+
+import (
+    "testing"
+
+    "fmt"
+    "time"
+    "sort"
+    "context"
+
+    "github.com/mokejp/linebotapi"
+    "github.com/mokejp/linebotapi/linebotapitest"
+)
+
+func Test_TextTemplate_Render(t *testing.T) {
+    tmpl, err := linebotapi.ParseTextTemplate("Hello, {{displayName}} ({{.Mid}})")
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    content, err := tmpl.Render(&linebotapi.Contact{Mid: "u1", DisplayName: "Brown"})
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if text := content.Content.(*linebotapi.MessageText).Text; text != "Hello, Brown (u1)" {
+        t.Errorf("excepted: 'Hello, Brown (u1)', actual: '%s'", text)
+    }
+
+    _, err = linebotapi.ParseTextTemplate("{{unknown}}")
+    if err == nil {
+        t.Error("err is nil")
+    }
+}
+
+func Test_BroadcastTemplate(t *testing.T) {
+    server := linebotapitest.NewServer(nil)
+    defer server.Close()
+    server.AddProfile(linebotapi.Contact{Mid: "u1", DisplayName: "Brown"})
+    server.AddProfile(linebotapi.Contact{Mid: "u2", DisplayName: "Cony"})
+    server.AddProfile(linebotapi.Contact{Mid: "u3", DisplayName: "Brown"})
+    client := server.APIClient()
+    tmpl, _ := linebotapi.ParseTextTemplate("Hello, {{displayName}}")
+
+    cache := linebotapi.NewProfileCache(client, 0, time.Minute)
+    report, err := client.BroadcastTemplate(context.Background(), []string{"u1", "u2", "u3", "u4", "u1"}, tmpl, cache, nil)
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    failed := report.Failed()
+    if len(report.Chunks) != 3 || len(failed) != 1 || failed[0].Err != linebotapi.ErrProfileNotFound || failed[0].To[0] != "u4" {
+        t.Errorf("unexpected report: %#v", report)
+    }
+
+    var sent []string
+    for _, event := range server.Events() {
+        for _, mid := range event.To {
+            sent = append(sent, mid + ":" + event.RawContent["text"].(string))
+        }
+    }
+    sort.Strings(sent)
+    expected := "[u1:Hello, Brown u2:Hello, Cony u3:Hello, Brown]"
+    if len(server.Events()) != 2 || fmt.Sprint(sent) != expected {
+        t.Errorf("excepted: %s, actual: %s", expected, fmt.Sprint(sent))
+    }
+
+    // profiles are cached
+    client.BroadcastTemplate(context.Background(), []string{"u1"}, tmpl, cache, nil)
+    if n := server.Requests(linebotapi.EndpointProfiles); n != 1 {
+        t.Errorf("excepted: 1, actual: %d", n)
+    }
+}
@@ -0,0 +1,145 @@
+package linebotapi
+
+import (
+    "context"
+    "strings"
+    "unicode"
+)
+
+// SplitText splits text into parts of at most maxLength characters, or
+// MaxTextLength when maxLength is 0 or less. A part ends at the last line
+// break, failing that at the last sentence end and then at the last space,
+// as long as this keeps at least half of maxLength in the part. Otherwise the
+// text is cut at the last character boundary, never inside a combined emoji
+// or a character with combining marks. Whitespace around cuts is dropped.
+func SplitText(text string, maxLength int) []string {
+    if maxLength <= 0 {
+        maxLength = MaxTextLength
+    }
+    var parts []string
+    runes := []rune(strings.TrimSpace(text))
+    for len(runes) > maxLength {
+        cut := splitPoint(runes, maxLength)
+        part := strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace)
+        if part != "" {
+            parts = append(parts, part)
+        }
+        runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+    }
+    if len(runes) > 0 {
+        parts = append(parts, string(runes))
+    }
+    return parts
+}
+
+// splitPoint returns the index in runes to cut the first part at.
+func splitPoint(runes []rune, maxLength int) int {
+    min := maxLength / 2
+    for _, isCut := range []func(runes []rune, i int) bool{isLineEnd, isSentenceEnd, isSpaceEnd} {
+        for i := maxLength; i > min; i-- {
+            if isCut(runes, i) && isGraphemeBoundary(runes, i) {
+                return i
+            }
+        }
+    }
+    for i := maxLength; i > 0; i-- {
+        if isGraphemeBoundary(runes, i) {
+            return i
+        }
+    }
+    // a single cluster longer than maxLength
+    return maxLength
+}
+
+func isLineEnd(runes []rune, i int) bool {
+    return runes[i - 1] == '\n'
+}
+
+func isSentenceEnd(runes []rune, i int) bool {
+    switch runes[i - 1] {
+    case '。', '！', '？', '．':
+        return true
+    case ' ':
+        return i >= 2 && strings.ContainsRune(".!?", runes[i - 2])
+    }
+    return false
+}
+
+func isSpaceEnd(runes []rune, i int) bool {
+    return unicode.IsSpace(runes[i - 1])
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isGraphemeBoundary reports whether runes can be cut before index i without
+// breaking a user-perceived character. It covers CR LF, combining marks,
+// variation selectors, emoji modifiers, ZWJ sequences and flag pairs.
+func isGraphemeBoundary(runes []rune, i int) bool {
+    if i <= 0 || i >= len(runes) {
+        return true
+    }
+    prev, cur := runes[i - 1], runes[i]
+    switch {
+    case prev == '\r' && cur == '\n':
+        return false
+    case prev == zeroWidthJoiner || isGraphemeExtend(cur):
+        return false
+    case isRegionalIndicator(prev) && isRegionalIndicator(cur):
+        n := 0
+        for j := i - 1; j >= 0 && isRegionalIndicator(runes[j]); j-- {
+            n++
+        }
+        return n % 2 == 0
+    }
+    return true
+}
+
+func isGraphemeExtend(r rune) bool {
+    return r == zeroWidthJoiner ||
+        unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+        (r >= 0xfe00 && r <= 0xfe0f) ||
+        (r >= 0x1f3fb && r <= 0x1f3ff) ||
+        (r >= 0xe0020 && r <= 0xe007f) ||
+        (r >= 0xe0100 && r <= 0xe01ef)
+}
+
+func isRegionalIndicator(r rune) bool {
+    return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// NewMessageTexts returns a text message for each part of SplitText(text, 0).
+func NewMessageTexts(text string) []*MessageContent {
+    parts := SplitText(text, 0)
+    contents := make([]*MessageContent, len(parts))
+    for i, part := range parts {
+        contents[i] = NewMessageText(part)
+    }
+    return contents
+}
+
+func (c *Client) SendLongText(to []string, text string) error {
+    return c.SendLongTextContext(context.Background(), to, text)
+}
+
+// SendLongTextContext sends the messages of NewMessageTexts with
+// SendMessages, up to MaxMessagesPerEvent at a time. Events are sent in order
+// and sending stops at the first error. Empty text is rejected with a
+// *FieldError.
+func (c *Client) SendLongTextContext(ctx context.Context, to []string, text string) error {
+    contents := NewMessageTexts(text)
+    if len(contents) == 0 {
+        return &FieldError{Field: "text"}
+    }
+    for len(contents) > 0 {
+        n := MaxMessagesPerEvent
+        if n > len(contents) {
+            n = len(contents)
+        }
+        err := c.SendMessagesContext(ctx, to, contents[:n], 0)
+        if err != nil {
+            return err
+        }
+        contents = contents[n:]
+    }
+    return nil
+}
@@ -0,0 +1,92 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "fmt"
+    "strings"
+    "unicode/utf8"
+    "net/http"
+    "net/http/httptest"
+    "encoding/json"
+)
+
+func Test_SplitText(t *testing.T) {
+    tests := []struct {
+        text string
+        maxLength int
+        parts []string
+    }{
+        {"short", 10, []string{"short"}},
+        {"  ", 10, nil},
+        {"first line\nsecond line", 16, []string{"first line", "second line"}},
+        {"One. Two three four.", 15, []string{"One. Two three", "four."}},
+        {"Hello there. General Kenobi", 16, []string{"Hello there.", "General Kenobi"}},
+        {"一文目です。二文目です。", 8, []string{"一文目です。", "二文目です。"}},
+        {"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+        // e + combining acute accent stays together
+        {"abcéfg", 4, []string{"abc", "éfg"}},
+        // family emoji joined with ZWJ
+        {"ab👨‍👩‍👧cd", 5, []string{"ab", "👨‍👩‍👧", "cd"}},
+        // flags are pairs of regional indicators
+        {"🇯🇵🇯🇵🇯🇵", 3, []string{"🇯🇵", "🇯🇵", "🇯🇵"}},
+        {"a\r\nb", 2, []string{"a", "b"}},
+    }
+    for i, test := range tests {
+        parts := SplitText(test.text, test.maxLength)
+        if fmt.Sprintf("%q", parts) != fmt.Sprintf("%q", test.parts) {
+            t.Errorf("%d: excepted: %q, actual: %q", i, test.parts, parts)
+        }
+    }
+
+    long := strings.Repeat("あいうえお。", 500)
+    for _, part := range SplitText(long, 0) {
+        if n := utf8.RuneCountInString(part); n > MaxTextLength || !strings.HasSuffix(part, "。") {
+            t.Errorf("unexpected part: %d characters", n)
+        }
+    }
+}
+
+func Test_SendLongText(t *testing.T) {
+    var events [][]interface{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var event Event
+        json.NewDecoder(r.Body).Decode(&event)
+        messages, _ := event.RawContent["messages"].([]interface{})
+        events = append(events, messages)
+        w.WriteHeader(200)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+    client.ValidateBeforeSend = true
+
+    var lines []string
+    for i := 0; i < 7; i++ {
+        lines = append(lines, fmt.Sprintf("%d", i) + strings.Repeat("x", MaxTextLength - 1))
+    }
+    err := client.SendLongText([]string{"abcdefg"}, strings.Join(lines, "\n"))
+    if err != nil {
+        t.Error(err)
+        return
+    }
+    if len(events) != 2 || len(events[0]) != MaxMessagesPerEvent || len(events[1]) != 2 {
+        t.Errorf("unexpected events: %d", len(events))
+        return
+    }
+    last := events[1][1].(map[string]interface{})["text"].(string)
+    if !strings.HasPrefix(last, "6") {
+        t.Errorf("unexpected order: %.10s", last)
+    }
+
+    err = client.SendLongText([]string{"abcdefg"}, " ")
+    if _, ok := err.(*FieldError); !ok {
+        t.Errorf("excepted: *FieldError, actual: %v", err)
+    }
+}
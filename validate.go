@@ -0,0 +1,171 @@
+package linebotapi
+
+import (
+    "fmt"
+    "math"
+    "net/url"
+    "unicode/utf8"
+)
+
+const (
+    // MaxTextLength is the maximum number of characters in a text message.
+    MaxTextLength = 1024
+    // MaxMessagesPerEvent is the maximum number of messages sent with
+    // SendMessages at once.
+    MaxMessagesPerEvent = 5
+)
+
+// Validator is implemented by the message types. Validate returns a
+// *FieldError naming the first field the server would reject; field names
+// are those of the JSON sent to the server.
+type Validator interface {
+    Validate() error
+}
+
+// Validate checks that ContentType matches the type of Content and validates
+// Content when it implements Validator. Custom Mapper types that do not are
+// accepted as is.
+func (c *MessageContent) Validate() error {
+    if c.Content == nil {
+        return &FieldError{Field: "content"}
+    }
+    if contentType, ok := messageContentType(c.Content); ok && contentType != c.ContentType {
+        return &FieldError{Field: "contentType", Expected: fmt.Sprint(contentType), Actual: fmt.Sprint(c.ContentType)}
+    }
+    if v, ok := c.Content.(Validator); ok {
+        return v.Validate()
+    }
+    return nil
+}
+
+// messageContentType returns the content type of the message types of this
+// package.
+func messageContentType(content Mapper) (uint8, bool) {
+    switch content.(type) {
+    case *MessageText:
+        return ContentTypeText, true
+    case *MessageImage:
+        return ContentTypeImage, true
+    case *MessageVideo:
+        return ContentTypeVideo, true
+    case *MessageAudio:
+        return ContentTypeAudio, true
+    case *MessageLocation:
+        return ContentTypeLocation, true
+    case *MessageSticker:
+        return ContentTypeSticker, true
+    case *MessageContact:
+        return ContentTypeContact, true
+    case *MessageRich:
+        return ContentTypeRich, true
+    }
+    return 0, false
+}
+
+// ValidateMessages validates a batch for SendMessages. Field names of
+// message errors are prefixed with "messages[i].".
+func ValidateMessages(contents []*MessageContent, notified int) error {
+    if len(contents) == 0 {
+        return &FieldError{Field: "messages"}
+    }
+    if len(contents) > MaxMessagesPerEvent {
+        return &FieldError{Field: "messages", Expected: fmt.Sprintf("at most %d messages", MaxMessagesPerEvent), Actual: fmt.Sprintf("%d messages", len(contents))}
+    }
+    if notified < 0 || notified >= len(contents) {
+        return &FieldError{Field: "messageNotified", Expected: fmt.Sprintf("0 to %d", len(contents) - 1), Actual: fmt.Sprint(notified)}
+    }
+    for i, content := range contents {
+        err := content.Validate()
+        if e, ok := err.(*FieldError); ok {
+            prefixed := *e
+            prefixed.Field = fmt.Sprintf("messages[%d].%s", i, e.Field)
+            return &prefixed
+        }
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (c *MessageText) Validate() error {
+    return checkText("text", c.Text)
+}
+
+func (c *MessageImage) Validate() error {
+    if err := checkURL("originalContentUrl", c.OriginalContentUrl); err != nil {
+        return err
+    }
+    return checkURL("previewImageUrl", c.PreviewImageUrl)
+}
+
+func (c *MessageVideo) Validate() error {
+    if err := checkURL("originalContentUrl", c.OriginalContentUrl); err != nil {
+        return err
+    }
+    return checkURL("previewImageUrl", c.PreviewImageUrl)
+}
+
+func (c *MessageAudio) Validate() error {
+    if err := checkURL("originalContentUrl", c.OriginalContentUrl); err != nil {
+        return err
+    }
+    if c.AudioLength <= 0 {
+        return &FieldError{Field: "contentMetadata.AUDLEN", Expected: "positive milliseconds", Actual: fmt.Sprint(c.AudioLength)}
+    }
+    return nil
+}
+
+func (c *MessageLocation) Validate() error {
+    if err := checkText("text", c.Text); err != nil {
+        return err
+    }
+    if c.Title == "" {
+        return &FieldError{Field: "location.title"}
+    }
+    if math.IsNaN(c.Latitude) || c.Latitude < -90 || c.Latitude > 90 {
+        return &FieldError{Field: "location.latitude", Expected: "-90 to 90", Actual: fmt.Sprint(c.Latitude)}
+    }
+    if math.IsNaN(c.Longitude) || c.Longitude < -180 || c.Longitude > 180 {
+        return &FieldError{Field: "location.longitude", Expected: "-180 to 180", Actual: fmt.Sprint(c.Longitude)}
+    }
+    return nil
+}
+
+func (c *MessageSticker) Validate() error {
+    if c.StickerId == "" {
+        return &FieldError{Field: "contentMetadata.STKID"}
+    }
+    if c.StickerPackageId == "" {
+        return &FieldError{Field: "contentMetadata.STKPKGID"}
+    }
+    return nil
+}
+
+func (c *MessageContact) Validate() error {
+    if !ValidMid(c.Mid) {
+        return &FieldError{Field: "contentMetadata.mid", Expected: "'u' followed by 32 hex digits", Actual: fmt.Sprintf("'%s'", c.Mid)}
+    }
+    return nil
+}
+
+func checkText(field, text string) error {
+    if text == "" {
+        return &FieldError{Field: field}
+    }
+    if n := utf8.RuneCountInString(text); n > MaxTextLength {
+        return &FieldError{Field: field, Expected: fmt.Sprintf("at most %d characters", MaxTextLength), Actual: fmt.Sprintf("%d characters", n)}
+    }
+    return nil
+}
+
+func checkURL(field, value string) error {
+    if value == "" {
+        return &FieldError{Field: field}
+    }
+    u, err := url.Parse(value)
+    if err != nil || u.Scheme != "https" || u.Host == "" {
+        return &FieldError{Field: field, Expected: "HTTPS URL", Actual: fmt.Sprintf("'%s'", value)}
+    }
+    return nil
+}
@@ -0,0 +1,114 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "math"
+    "strings"
+    "net/http"
+    "net/http/httptest"
+)
+
+func Test_MessageContent_Validate(t *testing.T) {
+    tests := []struct {
+        content *MessageContent
+        field string
+    }{
+        {NewMessageText("hello"), ""},
+        {NewMessageText(""), "text"},
+        {NewMessageText(strings.Repeat("あ", MaxTextLength)), ""},
+        {NewMessageText(strings.Repeat("あ", MaxTextLength + 1)), "text"},
+        {NewMessageImage("https://example.com/a.jpg", "https://example.com/b.jpg"), ""},
+        {NewMessageImage("http://example.com/a.jpg", "https://example.com/b.jpg"), "originalContentUrl"},
+        {NewMessageVideo("https://example.com/a.mp4", ""), "previewImageUrl"},
+        {NewMessageAudio("https://example.com/a.m4a", 0), "contentMetadata.AUDLEN"},
+        {NewMessageAudio("/a.m4a", 1000), "originalContentUrl"},
+        {NewMessageLocation("here", "title", 35.6, 139.7), ""},
+        {NewMessageLocation("here", "title", math.NaN(), 139.7), "location.latitude"},
+        {NewMessageLocation("here", "title", 35.6, math.Inf(1)), "location.longitude"},
+        {NewMessageLocation("here", "", 35.6, 139.7), "location.title"},
+        {NewMessageSticker("1", "", "100"), "contentMetadata.STKID"},
+        {NewMessageContact("u0123", "name"), "contentMetadata.mid"},
+        {NewMessageRich("http://example.com/rich", "alt", NewRichMarkup(1040)), "contentMetadata.DOWNLOAD_URL"},
+        {&MessageContent{ContentType: ContentTypeText}, "content"},
+        {&MessageContent{ContentType: ContentTypeImage, Content: &MessageText{Text: "hello"}}, "contentType"},
+        {&MessageContent{ContentType: ContentTypeText, Content: &MessageRich{}}, "contentType"},
+    }
+    for i, test := range tests {
+        err := test.content.Validate()
+        if test.field == "" {
+            if err != nil {
+                t.Errorf("%d: unexpected error: %v", i, err)
+            }
+            continue
+        }
+        fieldErr, ok := err.(*FieldError)
+        if !ok || fieldErr.Field != test.field {
+            t.Errorf("%d: excepted: %s, actual: %v", i, test.field, err)
+        }
+    }
+}
+
+func Test_ValidateMessages(t *testing.T) {
+    text := NewMessageText("hello")
+    tests := []struct {
+        contents []*MessageContent
+        notified int
+        field string
+    }{
+        {[]*MessageContent{text, text}, 1, ""},
+        {nil, 0, "messages"},
+        {[]*MessageContent{text, text, text, text, text, text}, 0, "messages"},
+        {[]*MessageContent{text}, 1, "messageNotified"},
+        {[]*MessageContent{text, NewMessageText("")}, 0, "messages[1].text"},
+    }
+    for i, test := range tests {
+        err := ValidateMessages(test.contents, test.notified)
+        if test.field == "" {
+            if err != nil {
+                t.Errorf("%d: unexpected error: %v", i, err)
+            }
+            continue
+        }
+        fieldErr, ok := err.(*FieldError)
+        if !ok || fieldErr.Field != test.field {
+            t.Errorf("%d: excepted: %s, actual: %v", i, test.field, err)
+        }
+    }
+}
+
+func Test_Client_ValidateBeforeSend(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.WriteHeader(200)
+    }))
+    defer server.Close()
+
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "abcdefg",
+        Mid: "abcdefg",
+    }
+    client := NewClient(cred)
+    client.BaseURL = server.URL
+
+    err := client.SendText([]string{"abcdefg"}, "")
+    if err != nil || requests != 1 {
+        t.Errorf("unexpected result: %v %d", err, requests)
+    }
+
+    client.ValidateBeforeSend = true
+    err = client.SendText([]string{"abcdefg"}, "")
+    if _, ok := err.(*FieldError); !ok || requests != 1 {
+        t.Errorf("unexpected result: %v %d", err, requests)
+    }
+    err = client.SendMessages([]string{"abcdefg"}, []*MessageContent{NewMessageText("hello"), NewMessageImage("ftp://example.com/a", "")}, 0)
+    if fieldErr, ok := err.(*FieldError); !ok || fieldErr.Field != "messages[1].originalContentUrl" || requests != 1 {
+        t.Errorf("unexpected result: %v %d", err, requests)
+    }
+    err = client.SendText([]string{"abcdefg"}, "hello")
+    if err != nil || requests != 2 {
+        t.Errorf("unexpected result: %v %d", err, requests)
+    }
+}
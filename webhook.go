@@ -0,0 +1,200 @@
+package linebotapi
+
+import (
+    "context"
+    "net/http"
+)
+
+// WebhookHandler is an http.Handler for the Bot API callback URL. It verifies
+// the request signature with ParseRequest and dispatches every event to the
+// callback registered for its content or operation type. Events without a
+// registered callback are ignored.
+//
+// The handler responds with 403 when the signature is missing or invalid,
+// 400 when the body cannot be decoded and 500 when a callback returns an
+// error. Otherwise it responds with 200. Single events that cannot be
+// decoded are passed to OnError and skipped without failing the request,
+// since redelivering them would not help.
+//
+// When Client and MediaStore are set, the content of image, video and audio
+// messages is stored with Client.StoreMessageContent before the callback is
+// called, so callbacks can read it from MediaStore by message id. The
+// download adds to the response time of the callback request; bots that
+// cannot afford this should leave MediaStore unset and store content from
+// their own workers. A failed download is handled like a callback error and
+// the callback is skipped.
+type WebhookHandler struct {
+    Credential *Credential
+    Client *Client
+    MediaStore MediaStore
+    // MaxMediaBytes limits the size of stored content; 0 does not limit it.
+    MaxMediaBytes int64
+
+    onText func(*http.Request, *TextMessage) error
+    onImage func(*http.Request, *ImageMessage) error
+    onVideo func(*http.Request, *VideoMessage) error
+    onAudio func(*http.Request, *AudioMessage) error
+    onLocation func(*http.Request, *LocationMessage) error
+    onSticker func(*http.Request, *StickerMessage) error
+    onContact func(*http.Request, *ContactMessage) error
+    onAdded func(*http.Request, *AddedOperation) error
+    onBlocked func(*http.Request, *BlockedOperation) error
+    onError func(*http.Request, error)
+}
+
+func NewWebhookHandler(cred *Credential) *WebhookHandler {
+    return &WebhookHandler{
+        Credential: cred,
+    }
+}
+
+func (h *WebhookHandler) OnText(f func(r *http.Request, m *TextMessage) error) {
+    h.onText = f
+}
+
+func (h *WebhookHandler) OnImage(f func(r *http.Request, m *ImageMessage) error) {
+    h.onImage = f
+}
+
+func (h *WebhookHandler) OnVideo(f func(r *http.Request, m *VideoMessage) error) {
+    h.onVideo = f
+}
+
+func (h *WebhookHandler) OnAudio(f func(r *http.Request, m *AudioMessage) error) {
+    h.onAudio = f
+}
+
+func (h *WebhookHandler) OnLocation(f func(r *http.Request, m *LocationMessage) error) {
+    h.onLocation = f
+}
+
+func (h *WebhookHandler) OnSticker(f func(r *http.Request, m *StickerMessage) error) {
+    h.onSticker = f
+}
+
+func (h *WebhookHandler) OnContact(f func(r *http.Request, m *ContactMessage) error) {
+    h.onContact = f
+}
+
+func (h *WebhookHandler) OnAdded(f func(r *http.Request, m *AddedOperation) error) {
+    h.onAdded = f
+}
+
+func (h *WebhookHandler) OnBlocked(f func(r *http.Request, m *BlockedOperation) error) {
+    h.onBlocked = f
+}
+
+// OnError registers a function that receives every error the handler runs
+// into, e.g. for logging. It is called from ServeHTTP while the request is
+// being handled, so it runs concurrently for concurrent requests. Callback
+// errors do not stop the remaining events from being dispatched.
+func (h *WebhookHandler) OnError(f func(r *http.Request, err error)) {
+    h.onError = f
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if r.Method != "POST" {
+        w.Header().Set("Allow", "POST")
+        http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+        return
+    }
+
+    events, err := ParseRequest(r, h.Credential)
+    if err != nil {
+        h.reportError(r, err)
+        if err == ErrSignatureNotFound || err == ErrInvalidSignature {
+            http.Error(w, err.Error(), http.StatusForbidden)
+        } else {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+        }
+        return
+    }
+
+    failed := false
+    for i := range events {
+        if events[i].Err != nil {
+            h.reportError(r, events[i].Err)
+            continue
+        }
+        err := h.storeMedia(r.Context(), events[i].Content)
+        if err == nil {
+            err = h.dispatch(r, events[i].Content)
+        }
+        if err != nil {
+            h.reportError(r, err)
+            failed = true
+        }
+    }
+    if failed {
+        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        return
+    }
+    w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) reportError(r *http.Request, err error) {
+    if h.onError != nil {
+        h.onError(r, err)
+    }
+}
+
+func (h *WebhookHandler) storeMedia(ctx context.Context, content Content) error {
+    if h.Client == nil || h.MediaStore == nil {
+        return nil
+    }
+    switch content.(type) {
+    case *ImageMessage, *VideoMessage, *AudioMessage:
+    default:
+        return nil
+    }
+    id := content.Header().Id
+    // redelivered events are already stored
+    _, err := h.MediaStore.Stat(ctx, id)
+    if err != ErrMediaNotFound {
+        return err
+    }
+    _, err = h.Client.StoreMessageContent(ctx, h.MediaStore, id, h.MaxMediaBytes)
+    return err
+}
+
+func (h *WebhookHandler) dispatch(r *http.Request, content Content) error {
+    switch m := content.(type) {
+    case *TextMessage:
+        if h.onText != nil {
+            return h.onText(r, m)
+        }
+    case *ImageMessage:
+        if h.onImage != nil {
+            return h.onImage(r, m)
+        }
+    case *VideoMessage:
+        if h.onVideo != nil {
+            return h.onVideo(r, m)
+        }
+    case *AudioMessage:
+        if h.onAudio != nil {
+            return h.onAudio(r, m)
+        }
+    case *LocationMessage:
+        if h.onLocation != nil {
+            return h.onLocation(r, m)
+        }
+    case *StickerMessage:
+        if h.onSticker != nil {
+            return h.onSticker(r, m)
+        }
+    case *ContactMessage:
+        if h.onContact != nil {
+            return h.onContact(r, m)
+        }
+    case *AddedOperation:
+        if h.onAdded != nil {
+            return h.onAdded(r, m)
+        }
+    case *BlockedOperation:
+        if h.onBlocked != nil {
+            return h.onBlocked(r, m)
+        }
+    }
+    return nil
+}
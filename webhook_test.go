@@ -0,0 +1,97 @@
+package linebotapi
+
+import (
+    "testing"
+
+    "bytes"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/base64"
+)
+
+func newSignedRequest(cred *Credential, body string) *http.Request {
+    mac := hmac.New(sha256.New, []byte(cred.ChannelSecret))
+    mac.Write([]byte(body))
+    req := httptest.NewRequest("POST", "/callback", bytes.NewBufferString(body))
+    req.Header.Set("X-LINE-ChannelSignature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+    return req
+}
+
+func Test_WebhookHandler_Dispatch(t *testing.T) {
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0000000000000000",
+        Mid: "0123456789abcdef0000000000000000",
+    }
+    var texts []string
+    var added []string
+    handler := NewWebhookHandler(cred)
+    handler.OnText(func(r *http.Request, m *TextMessage) error {
+        texts = append(texts, m.Text)
+        return nil
+    })
+    handler.OnAdded(func(r *http.Request, m *AddedOperation) error {
+        added = append(added, m.From)
+        return nil
+    })
+
+    w := httptest.NewRecorder()
+    handler.ServeHTTP(w, newSignedRequest(cred, `{"result":[` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"1","to":["abced"],"contentType":1,"text":"hello"}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"2","to":["abced"],"contentType":2}},` +
+        `{"content":{"toType":1,"createdTime":1460529367936,"from":"fghij","id":"3","to":["abced"],"opType":4}}]}`))
+    if w.Code != http.StatusOK {
+        t.Errorf("excepted: 200, actual: %d", w.Code)
+    }
+    if len(texts) != 1 || texts[0] != "hello" {
+        t.Errorf("unexpected texts: %v", texts)
+    }
+    if len(added) != 1 || added[0] != "fghij" {
+        t.Errorf("unexpected added: %v", added)
+    }
+}
+
+func Test_WebhookHandler_Status(t *testing.T) {
+    cred := &Credential{
+        ChannelId: 1234567890,
+        ChannelSecret: "0123456789abcdef0000000000000000",
+        Mid: "0123456789abcdef0000000000000000",
+    }
+    body := `{"result":[{"content":{"toType":1,"createdTime":1460529367936,"from":"abced","id":"1","to":["abced"],"contentType":1,"text":"hello"}}]}`
+    var reported []error
+    handler := NewWebhookHandler(cred)
+    handler.OnText(func(r *http.Request, m *TextMessage) error {
+        return errors.New("failed")
+    })
+    handler.OnError(func(r *http.Request, err error) {
+        reported = append(reported, err)
+    })
+
+    unsigned := httptest.NewRequest("POST", "/callback", bytes.NewBufferString(body))
+    tampered := newSignedRequest(cred, body)
+    tampered.Header.Set("X-LINE-ChannelSignature", base64.StdEncoding.EncodeToString([]byte("tampered")))
+    tests := []struct {
+        req *http.Request
+        code int
+    }{
+        {unsigned, http.StatusForbidden},
+        {tampered, http.StatusForbidden},
+        {newSignedRequest(cred, `{"result":[{"content":{"contentType":1}}]}`), http.StatusOK},
+        {newSignedRequest(cred, `{"result":`), http.StatusBadRequest},
+        {newSignedRequest(cred, body), http.StatusInternalServerError},
+        {httptest.NewRequest("GET", "/callback", nil), http.StatusMethodNotAllowed},
+    }
+    for i, test := range tests {
+        w := httptest.NewRecorder()
+        handler.ServeHTTP(w, test.req)
+        if w.Code != test.code {
+            t.Errorf("%d: excepted: %d, actual: %d", i, test.code, w.Code)
+        }
+    }
+    if len(reported) != 5 {
+        t.Errorf("excepted: 5, actual: %d", len(reported))
+    }
+}